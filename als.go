@@ -0,0 +1,482 @@
+package main
+
+import (
+	"container/heap"
+	"flag"
+	"log"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	alsFactors    = flag.Int("als_factors", 32, "number of latent factors to train")
+	alsIterations = flag.Int("als_iterations", 10, "number of ALS iterations")
+	alsLambda     = flag.Float64("als_lambda", 0.1, "ALS regularization")
+	alsAlpha      = flag.Float64("als_alpha", 40, "ALS confidence scaling of a favorite")
+)
+
+// The ALS model is stored as the ALSModel metadata under alsModelKey and the
+// Factors of every story and user under alsPrefix + their key.
+const (
+	alsPrefix     = "als:"
+	alsModelKey   = "als-model"
+	alsCandidates = 5000
+)
+
+// cmdTrainALS fits implicit feedback ALS factors to every user's favorite
+// stories and saves them to the database.
+func (s *server) cmdTrainALS() error {
+	start := time.Now()
+	var userKeys, storyKeys []string
+	storyIndex := make(map[string]int32)
+	var userRows [][]int32
+
+	if err := s.db.View(func(txn Txn) error {
+		return txn.Scan("user:", true, func(_ string, body []byte) error {
+			var u User
+			if err := u.Unmarshal(body); err != nil {
+				return err
+			}
+			if len(u.FavStories) == 0 {
+				return nil
+			}
+			row := make([]int32, len(u.FavStories))
+			for i, story := range u.FavStories {
+				idx, ok := storyIndex[story]
+				if !ok {
+					idx = int32(len(storyKeys))
+					storyIndex[story] = idx
+					storyKeys = append(storyKeys, story)
+				}
+				row[i] = idx
+			}
+			userKeys = append(userKeys, u.key())
+			userRows = append(userRows, row)
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+	log.Printf("Loaded %d users and %d stories in %s", len(userKeys), len(storyKeys), time.Since(start))
+
+	storyRows := make([][]int32, len(storyKeys))
+	for u, row := range userRows {
+		for _, i := range row {
+			storyRows[i] = append(storyRows[i], int32(u))
+		}
+	}
+
+	k := *alsFactors
+	r := rand.New(rand.NewSource(0))
+	userFactors := alsInit(r, len(userKeys), k)
+	storyFactors := alsInit(r, len(storyKeys), k)
+	for iter := 0; iter < *alsIterations; iter++ {
+		iterStart := time.Now()
+		alsSolveAll(storyFactors, userRows, userFactors, k)
+		alsSolveAll(userFactors, storyRows, storyFactors, k)
+		log.Printf("ALS iteration %d/%d took %s", iter+1, *alsIterations, time.Since(iterStart))
+	}
+
+	model := ALSModel{
+		Factors: int32(k),
+		Lambda:  float32(*alsLambda),
+		Alpha:   float32(*alsAlpha),
+		Yty:     alsGramian(storyFactors, k),
+		Trained: time.Now().Unix(),
+		Stories: int32(len(storyKeys)),
+		Users:   int32(len(userKeys)),
+	}
+
+	if err := s.deletePrefix(alsPrefix); err != nil {
+		return err
+	}
+	if err := s.saveFactors(storyKeys, storyFactors); err != nil {
+		return err
+	}
+	if err := s.saveFactors(userKeys, userFactors); err != nil {
+		return err
+	}
+	body, err := model.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := s.db.Update(func(txn Txn) error {
+		return txn.Set(alsModelKey, body)
+	}); err != nil {
+		return err
+	}
+	log.Printf("Trained ALS model in %s", time.Since(start))
+	return nil
+}
+
+func alsInit(r *rand.Rand, n, k int) [][]float32 {
+	factors := make([][]float32, n)
+	for i := range factors {
+		factors[i] = make([]float32, k)
+		for j := range factors[i] {
+			factors[i][j] = float32(r.NormFloat64() * 0.01)
+		}
+	}
+	return factors
+}
+
+// alsGramian returns the row major k x k matrix Y^T Y.
+func alsGramian(y [][]float32, k int) []float64 {
+	gram := make([]float64, k*k)
+	for _, row := range y {
+		for a := 0; a < k; a++ {
+			for b := 0; b < k; b++ {
+				gram[a*k+b] += float64(row[a]) * float64(row[b])
+			}
+		}
+	}
+	return gram
+}
+
+// alsSolveAll recomputes every row of out from the fixed factors, where rows[i]
+// are the indexes into fixed that row i favorited.
+func alsSolveAll(fixed [][]float32, rows [][]int32, out [][]float32, k int) {
+	gram := alsGramian(fixed, k)
+	jobs := make(chan int, 1024)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				weights := make([]float64, len(rows[i]))
+				for j := range weights {
+					weights[j] = 1
+				}
+				x, err := alsSolve(gram, fixed, rows[i], weights, nil, nil, k, *alsLambda, *alsAlpha)
+				if err != nil {
+					continue
+				}
+				for j := range x {
+					out[i][j] = float32(x[j])
+				}
+			}
+		}()
+	}
+	for i := range rows {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// alsSolve solves for the factors of a single user that favorited the rows pos
+// of fixed with the given weights and disliked the rows neg with negWeights:
+//
+//	(Y^T C Y + lambda I) x = Y^T C p
+func alsSolve(gram []float64, fixed [][]float32, pos []int32, weights []float64, neg []int32, negWeights []float64, k int, lambda, alpha float64) ([]float64, error) {
+	a := make([]float64, k*k)
+	copy(a, gram)
+	for i := 0; i < k; i++ {
+		a[i*k+i] += lambda
+	}
+	b := make([]float64, k)
+	addOuter := func(y []float32, c float64) {
+		for i := 0; i < k; i++ {
+			for j := 0; j < k; j++ {
+				a[i*k+j] += c * float64(y[i]) * float64(y[j])
+			}
+		}
+	}
+	for n, idx := range pos {
+		y := fixed[idx]
+		c := alpha * weights[n]
+		addOuter(y, c)
+		for i := 0; i < k; i++ {
+			b[i] += (1 + c) * float64(y[i])
+		}
+	}
+	for n, idx := range neg {
+		addOuter(fixed[idx], alpha*negWeights[n])
+	}
+	return choleskySolve(a, b, k)
+}
+
+// choleskySolve solves a x = b for a symmetric positive definite row major
+// k x k matrix a. a is overwritten.
+func choleskySolve(a, b []float64, k int) ([]float64, error) {
+	for j := 0; j < k; j++ {
+		sum := a[j*k+j]
+		for p := 0; p < j; p++ {
+			sum -= a[j*k+p] * a[j*k+p]
+		}
+		if sum <= 0 {
+			return nil, errors.New("matrix is not positive definite")
+		}
+		a[j*k+j] = math.Sqrt(sum)
+		for i := j + 1; i < k; i++ {
+			sum := a[i*k+j]
+			for p := 0; p < j; p++ {
+				sum -= a[i*k+p] * a[j*k+p]
+			}
+			a[i*k+j] = sum / a[j*k+j]
+		}
+	}
+	// Forward then back substitution with the lower triangle L L^T.
+	x := make([]float64, k)
+	for i := 0; i < k; i++ {
+		sum := b[i]
+		for p := 0; p < i; p++ {
+			sum -= a[i*k+p] * x[p]
+		}
+		x[i] = sum / a[i*k+i]
+	}
+	for i := k - 1; i >= 0; i-- {
+		sum := x[i]
+		for p := i + 1; p < k; p++ {
+			sum -= a[p*k+i] * x[p]
+		}
+		x[i] = sum / a[i*k+i]
+	}
+	return x, nil
+}
+
+// saveFactors writes the factors of each key in as few transactions as
+// possible.
+func (s *server) saveFactors(keys []string, factors [][]float32) error {
+	for i := 0; i < len(keys); {
+		start := i
+		if err := s.db.Update(func(txn Txn) error {
+			for i = start; i < len(keys); i++ {
+				f := Factors{Values: factors[i]}
+				body, err := f.Marshal()
+				if err != nil {
+					return err
+				}
+				// Commit what fits and continue in a new transaction.
+				if err := txn.Set(alsPrefix+keys[i], body); err == errTxnTooBig && i > start {
+					return nil
+				} else if err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletePrefix deletes all keys starting with prefix.
+func (s *server) deletePrefix(prefix string) error {
+	for {
+		var keys []string
+		if err := s.db.View(func(txn Txn) error {
+			return txn.Scan(prefix, false, func(key string, _ []byte) error {
+				if len(keys) >= 10000 {
+					return errStopScan
+				}
+				keys = append(keys, key)
+				return nil
+			})
+		}); err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		if err := s.db.Update(func(txn Txn) error {
+			for _, key := range keys {
+				if err := txn.Delete(key); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// alsModel is a trained ALS model loaded into memory.
+type alsModel struct {
+	meta    ALSModel
+	keys    []string
+	index   map[string]int32
+	factors [][]float32
+}
+
+// alsModel returns the current ALS model, loading it if it was retrained.
+func (s *server) alsModel() (*alsModel, error) {
+	var meta ALSModel
+	if err := s.db.View(func(txn Txn) error {
+		body, err := txn.Get(alsModelKey)
+		if err != nil {
+			return err
+		}
+		return meta.Unmarshal(body)
+	}); err == errNotFound {
+		return nil, errors.New("no ALS model trained")
+	} else if err != nil {
+		return nil, err
+	}
+
+	s.alsMu.Lock()
+	defer s.alsMu.Unlock()
+	if s.als != nil && s.als.meta.Trained == meta.Trained {
+		return s.als, nil
+	}
+
+	start := time.Now()
+	m := &alsModel{
+		meta:  meta,
+		index: make(map[string]int32, meta.Stories),
+	}
+	if err := s.db.View(func(txn Txn) error {
+		return txn.Scan(alsPrefix+"story:", true, func(key string, body []byte) error {
+			var f Factors
+			if err := f.Unmarshal(body); err != nil {
+				return err
+			}
+			key = key[len(alsPrefix):]
+			m.index[key] = int32(len(m.keys))
+			m.keys = append(m.keys, key)
+			m.factors = append(m.factors, f.Values)
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	log.Printf("Loaded ALS model with %d stories in %s", len(m.keys), time.Since(start))
+	s.als = m
+	return m, nil
+}
+
+func init() {
+	registerRecommender(alsRecommender{})
+}
+
+// alsRecommender ranks stories by the dot product of their ALS factors with
+// factors folded in from the seed stories.
+type alsRecommender struct{}
+
+func (alsRecommender) Name() string { return "als" }
+
+func (alsRecommender) Match(s *server, urls []string) ([]seed, error) {
+	return matchStoryURLs(s, urls)
+}
+
+func (alsRecommender) Recommend(s *server, seeds []seed, opts recOptions) (recResp, error) {
+	start := time.Now()
+	m, err := s.alsModel()
+	if err != nil {
+		return recResp{}, err
+	}
+	k := int(m.meta.Factors)
+
+	var pos, neg []int32
+	var weights, negWeights []float64
+	isSeed := make(map[string]bool, len(seeds))
+	var stats []*seedStats
+	for _, seed := range seeds {
+		isSeed[seed.Key] = true
+		idx, ok := m.index[seed.Key]
+		if !ok || seed.Weight == 0 {
+			continue
+		}
+		pos = append(pos, idx)
+		weights = append(weights, seed.Weight)
+		stats = append(stats, &seedStats{Weight: seed.Weight})
+	}
+	if len(pos) == 0 {
+		return recResp{}, errors.New("no ALS factors for the seed stories")
+	}
+	for _, seed := range opts.Negative {
+		isSeed[seed.Key] = true
+		if idx, ok := m.index[seed.Key]; ok {
+			neg = append(neg, idx)
+			negWeights = append(negWeights, seed.Weight)
+		}
+	}
+	x, err := alsSolve(m.meta.Yty, m.factors, pos, weights, neg, negWeights, k, float64(m.meta.Lambda), float64(m.meta.Alpha))
+	if err != nil {
+		return recResp{}, err
+	}
+
+	h := &scoreHeap{}
+	for i, y := range m.factors {
+		if isSeed[m.keys[i]] {
+			continue
+		}
+		score := 0.0
+		for j := 0; j < k; j++ {
+			score += x[j] * float64(y[j])
+		}
+		if h.Len() < alsCandidates {
+			heap.Push(h, scored{i, score})
+		} else if score > (*h)[0].score {
+			(*h)[0] = scored{i, score}
+			heap.Fix(h, 0)
+		}
+	}
+	recStories := make(map[string]float64, h.Len())
+	for _, sc := range *h {
+		recStories[m.keys[sc.idx]] = sc.score
+	}
+
+	seedKeys := make([]string, len(pos))
+	for i, idx := range pos {
+		seedKeys[i] = m.keys[idx]
+	}
+	seedStories, err := s.storiesByKeys(seedKeys)
+	if err != nil {
+		return recResp{}, err
+	}
+	for i, st := range seedStories {
+		stats[i].Story = st
+	}
+
+	rsl, err := s.rankStories(recStories, opts)
+	if err != nil {
+		return recResp{}, err
+	}
+	sOut, err := s.pageStories(rsl, recStories, opts)
+	if err != nil {
+		return recResp{}, err
+	}
+	for _, st := range sOut {
+		st.annotate()
+		st.Score = float32(recStories[st.key()])
+	}
+	resp := recResp{
+		Stories: sOut,
+		Story:   seedStories[0],
+		Seeds:   stats,
+		Cursor:  nextCursor(sOut, recStories, opts),
+		Stats: respStats{
+			StoryCount: len(recStories),
+		},
+	}
+	log.Printf("alsRecommender(%q) took %s, stats %+v", seedStories[0].Title, time.Since(start), resp.Stats)
+	return resp, nil
+}
+
+type scored struct {
+	idx   int
+	score float64
+}
+
+// scoreHeap is a min heap of scores.
+type scoreHeap []scored
+
+func (h scoreHeap) Len() int            { return len(h) }
+func (h scoreHeap) Less(i, j int) bool  { return h[i].score < h[j].score }
+func (h scoreHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *scoreHeap) Push(x interface{}) { *h = append(*h, x.(scored)) }
+func (h *scoreHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
@@ -16,14 +17,12 @@ import (
 
 func init() {
 	scrapers = append(scrapers, scrapeAO3)
-	recommenders = append(recommenders, recommendAO3)
+	storyURLs = append(storyURLs, storyURL{ao3Regex, AO3})
+	userURLs = append(userURLs, userURL{ao3UserRegex, AO3, true})
 }
 
 var ao3Regex = regexp.MustCompile(`^https?:\/\/archiveofourown.org\/works\/(\d+).*$`)
-
-func recommendAO3(s *server, urls []string, limit, offset int) (recResp, error) {
-	return recommendGeneric(s, urls, limit, offset, ao3Regex, AO3)
-}
+var ao3UserRegex = regexp.MustCompile(`^https?:\/\/archiveofourown.org\/users\/([^\/?#]+).*$`)
 
 func getLatestAO3() (int, error) {
 	doc, err := goquery.NewDocument("https://archiveofourown.org/works")
@@ -158,43 +157,49 @@ func fetchAO3(s *Story, doc *goquery.Document, sr *server) error {
 	stats, _ := doc.Find("dd.stats dl.stats").Html()
 	fandoms, _ := doc.Find(".fandom li").Html()
 	s.Desc += "<div class='xgray'>" + fandoms + " - " + stats + "</div>"
+	// Works can be in several fandoms, the first one is used as the category.
+	s.Category = strings.TrimSpace(doc.Find(".fandom li").First().Text())
 
 	var err error
-	u := User{
-		Site:   AO3,
-		Exists: true,
-	}
+	now := time.Now().Unix()
 	doc.Find("#kudos a").Each(func(i int, sel *goquery.Selection) {
 		link := sel.AttrOr("href", "")
-		if !strings.HasPrefix(link, "/users/") {
+		if err != nil || !strings.HasPrefix(link, "/users/") {
 			return
 		}
 
 		s.Favorites++
 
 		name := strings.ToLower(sel.Text())
-		u.Id = name
-		u.Name = name
-		if u.checkExists(sr) {
-			var u2 User
-			u2, err = sr.userByKey(u.key())
-			if err != nil {
-				return
-			}
-			u.FavAuthors = u2.FavAuthors
-			u.FavStories = u2.FavStories
-			u.FavedBy = u2.FavedBy
-		}
-		if !strContains(u.FavStories, s.key()) {
-			u.FavStories = append(u.FavStories, s.key())
+		u := User{
+			Id:     name,
+			Name:   name,
+			Site:   AO3,
+			Exists: true,
 		}
-		err = u.save(sr)
-		if err != nil {
-			return
+		if !strContains(s.FavedBy, u.key()) {
+			s.FavedBy = append(s.FavedBy, u.key())
 		}
+		err = sr.addFavStory(u, s.key(), now)
 	})
 	if err != nil {
 		return err
 	}
-	return s.save(sr)
+	// Link the work to the same title by the same author on other sites.
+	author := doc.Find(`.byline a[rel="author"]`).First()
+	bits := strings.Split(author.AttrOr("href", ""), "/")
+	if len(bits) >= 3 && bits[1] == "users" {
+		// The href is /users/<name>/pseuds/<pseud>, the pseud being the pen name.
+		pseud, err := url.PathUnescape(bits[len(bits)-1])
+		if err != nil {
+			pseud = author.Text()
+		}
+		s.AuthorId = strings.ToLower(bits[2])
+		s.AuthorName = pseud
+		authorKey := User{Id: s.AuthorId, Site: AO3}.key()
+		if err := sr.saveTwin(s.Title, pseud, authorKey, s.key()); err != nil {
+			return err
+		}
+	}
+	return sr.mergeStory(*s)
 }
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"net/http"
+)
+
+// favAuthorPrefix indexes the fans of an author:
+//
+//	favauthor:<author user key>|<fan user key> -> ""
+//
+// Entries aren't removed when a fan unfavorites the author, so the fans'
+// FavAuthors have to be checked.
+const favAuthorPrefix = "favauthor:"
+
+// rankAuthors ranks authors by how many of the fans favorited them, each fan
+// counting with its weight in fanWeights. An author also gets credit for every
+// fan favorite of the candidate stories they wrote, counts being the
+// co-favorite counts of the candidate stories. Authors that haven't been
+// fetched are left out.
+func (s *server) rankAuthors(fans []*User, fanWeights, counts map[string]float64) ([]string, error) {
+	recAuthors := make(map[string]float64)
+	for _, fan := range fans {
+		weight := fanWeights[fan.key()]
+		for _, id := range fan.FavAuthors {
+			author := User{Id: id, Site: fan.Site}
+			recAuthors[author.key()] += weight
+		}
+	}
+
+	candidates := make([]string, 0, len(counts))
+	for story := range counts {
+		candidates = append(candidates, story)
+	}
+	storyAuthors, err := s.storyAuthors(candidates)
+	if err != nil {
+		return nil, err
+	}
+	for story, author := range storyAuthors {
+		recAuthors[author] += counts[story]
+	}
+	return s.existingKeys(sortMap(recAuthors))
+}
+
+// existingUsersByKeys is like usersByKeys but skips users that haven't been
+// fetched yet.
+func (s *server) existingUsersByKeys(keys []string) ([]*User, error) {
+	arr := make([]*User, 0, len(keys))
+
+	if err := s.db.View(func(txn Txn) error {
+		for _, key := range keys {
+			u, err := getUser(txn, key)
+			if err == errNotFound {
+				continue
+			} else if err != nil {
+				return err
+			}
+			arr = append(arr, u)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return arr, nil
+}
+
+// authorFans returns the keys of the users that favorited the author or one of
+// their stories. Fans indexed under favAuthorPrefix that no longer have the
+// author in their FavAuthors are left out.
+func (s *server) authorFans(author User) ([]string, error) {
+	seen := map[string]bool{author.key(): true}
+	var fans []string
+	add := func(fan string) {
+		if !seen[fan] {
+			seen[fan] = true
+			fans = append(fans, fan)
+		}
+	}
+	if err := s.forStories(author.Stories, func(key string, st *Story) {
+		for _, fan := range st.FavedBy {
+			add(fan)
+		}
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.db.View(func(txn Txn) error {
+		favs, err := edges(txn, favAuthorPrefix, author.key())
+		if err != nil {
+			return err
+		}
+		for _, fan := range favs {
+			if seen[fan] {
+				continue
+			}
+			u, err := getUser(txn, fan)
+			if err == errNotFound {
+				continue
+			} else if err != nil {
+				return err
+			}
+			if strContains(u.FavAuthors, author.Id) {
+				add(fan)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return fans, nil
+}
+
+// similarAuthor is an author whose fans overlap with another author's.
+type similarAuthor struct {
+	Author *User
+	Score  float64
+	// FavAuthor is the number of the fans that favorited the author.
+	FavAuthor int
+	// FavStories is the number of favorites the fans gave the author's stories.
+	FavStories int
+}
+
+type similarAuthorsResp struct {
+	Author  *User
+	Authors []*similarAuthor
+	// Fans is the number of fans of the author.
+	Fans int
+}
+
+// similarAuthors ranks the authors favorited by the fans of the author. Each
+// fan that favorited an author, and each fan favorite of one of their stories,
+// counts once towards the score. Linked identities of the author are left out.
+func (s *server) similarAuthors(author User, offset, limit int) (similarAuthorsResp, error) {
+	fanKeys, err := s.authorFans(author)
+	if err != nil {
+		return similarAuthorsResp{}, err
+	}
+	fans, err := s.existingUsersByKeys(fanKeys)
+	if err != nil {
+		return similarAuthorsResp{}, err
+	}
+	own := make(map[string]bool, len(author.Stories))
+	for _, story := range author.Stories {
+		own[story] = true
+	}
+
+	stats := make(map[string]*similarAuthor)
+	stat := func(key string) *similarAuthor {
+		sa, ok := stats[key]
+		if !ok {
+			sa = &similarAuthor{}
+			stats[key] = sa
+		}
+		return sa
+	}
+	storyCounts := make(map[string]int)
+	for _, fan := range fans {
+		for _, id := range fan.FavAuthors {
+			stat(User{Id: id, Site: fan.Site}.key()).FavAuthor++
+		}
+		for _, story := range fan.FavStories {
+			if !own[story] {
+				storyCounts[story]++
+			}
+		}
+	}
+	keys := make([]string, 0, len(storyCounts))
+	for story := range storyCounts {
+		keys = append(keys, story)
+	}
+	storyAuthors, err := s.storyAuthors(keys)
+	if err != nil {
+		return similarAuthorsResp{}, err
+	}
+	for story, a := range storyAuthors {
+		stat(a).FavStories += storyCounts[story]
+	}
+
+	linked, err := s.linkedAuthors(author.key())
+	if err != nil {
+		return similarAuthorsResp{}, err
+	}
+	delete(stats, author.key())
+	for _, key := range linked {
+		delete(stats, key)
+	}
+	scores := make(map[string]float64, len(stats))
+	for key, sa := range stats {
+		sa.Score = float64(sa.FavAuthor + sa.FavStories)
+		scores[key] = sa.Score
+	}
+
+	// Only authors that have been fetched can be listed.
+	ranked, err := s.existingKeys(sortMap(scores))
+	if err != nil {
+		return similarAuthorsResp{}, err
+	}
+	users, err := s.existingUsersByKeys(pageKeys(ranked, offset, limit))
+	if err != nil {
+		return similarAuthorsResp{}, err
+	}
+	resp := similarAuthorsResp{
+		Author: &author,
+		Fans:   len(fans),
+	}
+	for _, u := range users {
+		sa := stats[u.key()]
+		sa.Author = u
+		resp.Authors = append(resp.Authors, sa)
+	}
+	return resp, nil
+}
+
+func (s *server) handleSimilarAuthors(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	limit := requestFormInt(r, "limit", 20)
+	offset := requestFormInt(r, "offset", 0)
+	if limit > 200 || limit < 0 {
+		http.Error(w, "limit must be  <= 200 && >= 0", 400)
+		return
+	}
+	if offset < 0 {
+		http.Error(w, "offset must be  >= 0", 400)
+		return
+	}
+	author, ok := matchUserURL(s, r.FormValue("url"))
+	if !ok {
+		http.Error(w, "unknown author", 400)
+		return
+	}
+	resp, err := s.similarAuthors(author, offset, limit)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSONP(w, r, resp)
+}
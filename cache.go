@@ -0,0 +1,199 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	rankingCacheSize = flag.Int("ranking_cache_size", 100, "number of recommendation rankings to cache")
+	rankingCacheTTL  = flag.Duration("ranking_cache_ttl", 10*time.Minute, "how long cached recommendation rankings are used")
+)
+
+// ranking is the unpaginated result of recommendationStory for a seed set.
+// Cached rankings are shared between requests and must not be modified.
+type ranking struct {
+	// seeds are the seeds the ranking was computed with, seedStories[i] being
+	// the story of seeds[i]. Requests with the seeds in a different order
+	// share the ranking.
+	seeds       []seed
+	seedStories []*Story
+	stats       []*seedStats
+	usersByKey  map[string]*User
+	// neighbors are the indexed neighbors of the seeds if the ranking was
+	// read from the neighbor index instead of usersByKey.
+	neighbors map[string]*Neighbors
+	fans      int
+	favorites int
+	// counts are the weighted co-favorite counts, shared the number of seed
+	// fans that favorited and scores the final scores of the candidates.
+	counts map[string]float64
+	shared map[string]int
+	scores map[string]float64
+	// stories and authors are all of the candidates in ranked order.
+	stories []string
+	authors []string
+}
+
+// seedStory returns the story of the seed.
+func (r *ranking) seedStory(key string) *Story {
+	for i, seed := range r.seeds {
+		if seed.Key == key {
+			return r.seedStories[i]
+		}
+	}
+	return r.seedStories[0]
+}
+
+type cachedRanking struct {
+	*ranking
+	created time.Time
+	// seeds are the positive and negative seed keys.
+	seeds []string
+}
+
+// rankingCache caches rankings by seed set and ranking options. Entries are
+// dropped when a seed story's FavedBy changes.
+type rankingCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedRanking
+	// bySeed maps seed keys to the keys of the entries using them.
+	bySeed map[string]map[string]bool
+	// pending counts the rankings being computed from each seed and gens the
+	// invalidations of those seeds since, so rankings computed from stale data
+	// aren't added.
+	pending map[string]int
+	gens    map[string]uint64
+}
+
+func newRankingCache() *rankingCache {
+	return &rankingCache{
+		entries: make(map[string]*cachedRanking),
+		bySeed:  make(map[string]map[string]bool),
+		pending: make(map[string]int),
+		gens:    make(map[string]uint64),
+	}
+}
+
+// rankingSeeds returns the positive and negative seed keys.
+func rankingSeeds(seeds, negative []seed) []string {
+	keys := make([]string, 0, len(seeds)+len(negative))
+	for _, seeds := range [][]seed{seeds, negative} {
+		for _, seed := range seeds {
+			keys = append(keys, seed.Key)
+		}
+	}
+	return keys
+}
+
+// rankingKey identifies the ranking of the seeds with the options that affect
+// it. Pagination, filters and diversity are applied to the cached ranking.
+func rankingKey(name string, seeds []seed, opts recOptions) string {
+	seedKey := func(seeds []seed) string {
+		parts := make([]string, len(seeds))
+		for i, seed := range seeds {
+			parts[i] = fmt.Sprintf("%s*%g", seed.Key, seed.Weight)
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprintf("%s|%s|%s|%g|%+v|%s", name, seedKey(seeds), seedKey(opts.Negative), opts.Novelty, opts.Recency, opts.IgnoreUser)
+}
+
+// get returns the cached ranking, if any. Otherwise the caller computes the
+// ranking and has to pass it, or nil if that failed, to put with the returned
+// generations of the seeds.
+func (c *rankingCache) get(key string, seeds []string) (*ranking, []uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if ok && time.Since(e.created) > *rankingCacheTTL {
+		c.remove(key)
+		ok = false
+	}
+	if ok {
+		return e.ranking, nil
+	}
+	gens := make([]uint64, len(seeds))
+	for i, seed := range seeds {
+		c.pending[seed]++
+		gens[i] = c.gens[seed]
+	}
+	return nil, gens
+}
+
+// put adds a ranking computed after get returned gens, evicting the oldest
+// entry if the cache is full. The ranking isn't added if one of its seeds was
+// invalidated since.
+func (c *rankingCache) put(key string, seeds []string, gens []uint64, r *ranking) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stale := false
+	for i, seed := range seeds {
+		if c.gens[seed] != gens[i] {
+			stale = true
+		}
+		c.pending[seed]--
+		if c.pending[seed] <= 0 {
+			delete(c.pending, seed)
+			delete(c.gens, seed)
+		}
+	}
+	if r == nil || stale || *rankingCacheSize <= 0 {
+		return
+	}
+	c.remove(key)
+	for len(c.entries) >= *rankingCacheSize {
+		var oldest string
+		var oldestTime time.Time
+		for k, e := range c.entries {
+			if oldest == "" || e.created.Before(oldestTime) {
+				oldest, oldestTime = k, e.created
+			}
+		}
+		c.remove(oldest)
+	}
+
+	e := &cachedRanking{ranking: r, created: time.Now(), seeds: seeds}
+	for _, seed := range seeds {
+		if c.bySeed[seed] == nil {
+			c.bySeed[seed] = make(map[string]bool)
+		}
+		c.bySeed[seed][key] = true
+	}
+	c.entries[key] = e
+}
+
+func (c *rankingCache) remove(key string) {
+	e, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+	for _, seed := range e.seeds {
+		delete(c.bySeed[seed], key)
+		if len(c.bySeed[seed]) == 0 {
+			delete(c.bySeed, seed)
+		}
+	}
+}
+
+// invalidate drops the rankings using the story as a seed, including the ones
+// being computed.
+func (c *rankingCache) invalidate(story string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pending[story] > 0 {
+		c.gens[story]++
+	}
+	for key := range c.bySeed[story] {
+		c.remove(key)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// cursor is the opaque position of the next page of recommendations. Pages
+// continue after the score and key of the last story shown, so they stay
+// consistent when the data changes between requests. Diversified rankings
+// don't follow the score order and continue from an offset instead.
+//
+// Authors are paged by offset, which cursors after a story carry in Authors.
+type cursor struct {
+	Score   float64 `json:"s,omitempty"`
+	Key     string  `json:"k,omitempty"`
+	Offset  int     `json:"o,omitempty"`
+	Authors int     `json:"a,omitempty"`
+}
+
+// before reports whether the cursor ranks before the item, i.e. the item is on
+// a later page.
+func (c cursor) before(score float64, key string) bool {
+	if score != c.Score {
+		return score < c.Score
+	}
+	return key > c.Key
+}
+
+func (c cursor) encode() string {
+	body, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(body)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	body, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, errors.New("invalid cursor")
+	}
+	if err := json.Unmarshal(body, &c); err != nil || c.Offset < 0 || c.Authors < 0 {
+		return cursor{}, errors.New("invalid cursor")
+	}
+	return c, nil
+}
+
+// afterCursor returns the keys, ranked by scores, that come after the cursor.
+func afterCursor(keys []string, scores map[string]float64, after *cursor) []string {
+	if after == nil {
+		return keys
+	}
+	i := sort.Search(len(keys), func(i int) bool {
+		return after.before(scores[keys[i]], keys[i])
+	})
+	return keys[i:]
+}
+
+// nextCursor returns the cursor of the page after stories, or "" if it was the
+// last page.
+func nextCursor(stories []*Story, scores map[string]float64, opts recOptions) string {
+	if len(stories) == 0 || len(stories) < opts.Limit {
+		return ""
+	}
+	if opts.Diversity > 0 {
+		return cursor{Offset: opts.Offset + len(stories)}.encode()
+	}
+	last := stories[len(stories)-1].key()
+	return cursor{Score: scores[last], Key: last, Authors: authorOffset(opts) + opts.Limit}.encode()
+}
+
+// authorOffset returns the offset of the page of authors selected by opts.
+func authorOffset(opts recOptions) int {
+	if opts.After != nil {
+		return opts.After.Authors
+	}
+	return opts.Offset
+}
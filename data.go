@@ -2,10 +2,8 @@ package main
 
 import (
 	"log"
-	"regexp"
 	"time"
 
-	"github.com/dgraph-io/badger"
 	"github.com/pkg/errors"
 )
 
@@ -57,13 +55,13 @@ const (
 )
 
 func (s *server) keyExists(key string) bool {
-	err := s.db.View(func(txn *badger.Txn) error {
-		_, err := txn.Get([]byte(key))
+	err := s.db.View(func(txn Txn) error {
+		_, err := txn.Get(key)
 		return err
 	})
 	if err == nil {
 		return true
-	} else if err == badger.ErrKeyNotFound {
+	} else if err == errNotFound {
 		return false
 	}
 	panic(err)
@@ -79,7 +77,7 @@ func (s Story) checkExists(sr *server) bool {
 
 func (s Story) checkExistsTitle(sr *server) bool {
 	story, err := sr.storyByKey(s.key())
-	if err == badger.ErrKeyNotFound {
+	if err == errNotFound {
 		return false
 	} else if err != nil {
 		panic(err)
@@ -92,14 +90,81 @@ func (u User) key() string {
 	return "user:" + Site_name[int32(u.Site)] + ":" + u.Id
 }
 
+// markSeen records when the favorite story was first seen.
+func (u *User) markSeen(story string, now int64) {
+	if u.FavSeen == nil {
+		u.FavSeen = make(map[string]int64)
+	}
+	if _, ok := u.FavSeen[story]; !ok {
+		u.FavSeen[story] = now
+	}
+}
+
 func (u User) save(s *server) error {
+	return s.db.Update(func(txn Txn) error {
+		return setUser(txn, u)
+	})
+}
+
+func setUser(txn Txn, u User) error {
 	id := u.key()
 	body, err := u.Marshal()
 	if err != nil {
 		return err
 	}
-	return s.db.Update(func(txn *badger.Txn) error {
-		return txn.Set([]byte(id), body)
+	if err := txn.Set(id, body); err != nil {
+		return err
+	}
+	for _, author := range u.FavAuthors {
+		if err := setEdge(txn, favAuthorPrefix, User{Id: author, Site: u.Site}.key(), id, nil); err != nil {
+			return err
+		}
+	}
+	return markDirty(txn, u.FavStories)
+}
+
+// maxConflictRetries is how many times a transaction is retried after
+// conflicting with a concurrent write.
+const maxConflictRetries = 20
+
+// updateRetry is db.Update, retrying fn if the transaction conflicts. fn has to
+// read everything it modifies in txn for the conflicts to be detected.
+func (s *server) updateRetry(fn func(txn Txn) error) error {
+	for i := 0; ; i++ {
+		err := s.db.Update(fn)
+		if err != errConflict || i >= maxConflictRetries {
+			return err
+		}
+	}
+}
+
+// updateUser atomically replaces the user with fn(old), old being nil if the
+// user isn't in the database.
+func (s *server) updateUser(key string, fn func(old *User) User) error {
+	return s.updateRetry(func(txn Txn) error {
+		old, err := getUser(txn, key)
+		if err == errNotFound {
+			old = nil
+		} else if err != nil {
+			return err
+		}
+		return setUser(txn, fn(old))
+	})
+}
+
+// addFavStory adds the story to the user's favorites, creating the user if
+// needed.
+func (s *server) addFavStory(u User, story string, now int64) error {
+	return s.updateUser(u.key(), func(old *User) User {
+		if old != nil {
+			u = *old
+			u.Exists = true
+		}
+		if !strContains(u.FavStories, story) {
+			u.FavStories = append(u.FavStories, story)
+		}
+		u.markSeen(story, now)
+		return u
 	})
 }
 
@@ -117,22 +182,14 @@ func (s *server) storyByKey(key string) (Story, error) {
 func (s *server) storiesByKeys(keys []string) ([]*Story, error) {
 	stories := make([]*Story, 0, len(keys))
 
-	if err := s.db.View(func(txn *badger.Txn) error {
+	if err := s.db.View(func(txn Txn) error {
 		for _, key := range keys {
-			item, err := txn.Get([]byte(key))
+			st, err := getStory(txn, key)
 			if err != nil {
 				return err
 			}
-			body, err := item.Value()
-			if err != nil {
-				return err
-			}
-			s := Story{}
-			if err := s.Unmarshal(body); err != nil {
-				return err
-			}
-			s.annotate()
-			stories = append(stories, &s)
+			st.annotate()
+			stories = append(stories, st)
 		}
 		return nil
 	}); err != nil {
@@ -155,33 +212,69 @@ func (s *server) userByKey(key string) (User, error) {
 func (s *server) usersByKeys(keys []string) ([]*User, error) {
 	arr := make([]*User, 0, len(keys))
 
-	if err := s.db.View(func(txn *badger.Txn) error {
+	if err := s.db.View(func(txn Txn) error {
 		for _, key := range keys {
-			item, err := txn.Get([]byte(key))
+			u, err := getUser(txn, key)
 			if err != nil {
 				return err
 			}
-			body, err := item.Value()
-			if err != nil {
+			arr = append(arr, u)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return arr, nil
+}
+
+// forStories calls fn with each of the stories. Missing stories are skipped.
+func (s *server) forStories(keys []string, fn func(key string, st *Story)) error {
+	return s.db.View(func(txn Txn) error {
+		for _, key := range keys {
+			st, err := getStory(txn, key)
+			if err == errNotFound {
+				continue
+			} else if err != nil {
 				return err
 			}
-			v := User{}
-			if err := v.Unmarshal(body); err != nil {
+			fn(key, st)
+		}
+		return nil
+	})
+}
+
+// existingKeys returns the keys that are in the database.
+func (s *server) existingKeys(keys []string) ([]string, error) {
+	var out []string
+	if err := s.db.View(func(txn Txn) error {
+		for _, key := range keys {
+			_, err := txn.Get(key)
+			if err == errNotFound {
+				continue
+			} else if err != nil {
 				return err
 			}
-			arr = append(arr, &v)
+			out = append(out, key)
 		}
 		return nil
 	}); err != nil {
 		return nil, err
 	}
-	return arr, nil
+	return out, nil
 }
 
 func (s Story) key() string {
 	return "story:" + Site_name[int32(s.Site)] + ":" + itoa(s.Id)
 }
 
+// authorKey returns the user key of the story's author, or "" if it's unknown.
+func (s Story) authorKey() string {
+	if s.AuthorId == "" {
+		return ""
+	}
+	return User{Id: s.AuthorId, Site: s.Site}.key()
+}
+
 func strContains(arr []string, str string) bool {
 	for _, s := range arr {
 		if s == str {
@@ -192,40 +285,88 @@ func strContains(arr []string, str string) bool {
 }
 
 func (s Story) save(sr *server) error {
-	id := s.key()
-	body, err := s.Marshal()
-	if err != nil {
+	return sr.updateStory(s.key(), func(*Story) Story {
+		return s
+	})
+}
+
+// updateStory atomically replaces the story with fn(old), old being nil if the
+// story isn't in the database, and updates its index entries.
+func (s *server) updateStory(key string, fn func(old *Story) Story) error {
+	var oldFans, newFans []string
+	if err := s.updateRetry(func(txn Txn) error {
+		oldFans = nil
+		old, err := getStory(txn, key)
+		if err == errNotFound {
+			old = nil
+		} else if err != nil {
+			return err
+		} else {
+			oldFans = old.FavedBy
+		}
+		st := fn(old)
+		newFans = st.FavedBy
+		if err := indexStory(txn, old, &st); err != nil {
+			return err
+		}
+		return setStory(txn, st)
+	}); err != nil {
 		return err
 	}
-	return sr.db.Update(func(txn *badger.Txn) error {
-		return txn.Set([]byte(id), body)
-	})
+	// Cached rankings seeded by the story are stale if its fans changed.
+	if !stringsEqual(oldFans, newFans) {
+		s.rankings.invalidate(key)
+	}
+	return nil
 }
 
-func recommendGeneric(s *server, urls []string, limit, offset int, reg *regexp.Regexp, site Site) (recResp, error) {
-	var matches []string
-	for _, url := range urls {
-		if submatches := reg.FindStringSubmatch(url); len(submatches) == 2 {
-			st := Story{
-				Id:   atoi(submatches[1]),
-				Site: site,
-			}
-			if st.checkExistsTitle(s) {
-				matches = append(matches, st.key())
+// mergeStory saves the story, keeping the fans of the stored story and its
+// author if st doesn't have one.
+func (s *server) mergeStory(st Story) error {
+	return s.updateStory(st.key(), func(old *Story) Story {
+		if old == nil {
+			return st
+		}
+		merged := st
+		if merged.AuthorId == "" {
+			merged.AuthorId = old.AuthorId
+			merged.AuthorName = old.AuthorName
+		}
+		merged.FavedBy = append([]string(nil), old.FavedBy...)
+		for _, fan := range st.FavedBy {
+			if !strContains(merged.FavedBy, fan) {
+				merged.FavedBy = append(merged.FavedBy, fan)
 			}
 		}
+		return merged
+	})
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
 	}
-	if len(matches) > 0 {
-		return recommendationStory(s, matches, limit, offset)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
 	}
-	return recResp{}, errStoryNotFound
+	return true
 }
 
 type recResp struct {
 	Stories []*Story
 	Authors []*User
 	Story   *Story
-	Stats   respStats
+	Seeds   []*seedStats
+	// Explanations[i] explains why Stories[i] was recommended. It's only set by
+	// recommenders that can explain their results.
+	Explanations []*explanation
+	Stats        respStats
+	// Cursor continues with the next page, empty on the last page.
+	Cursor string
+	// Experiment is the experiment variant that served the request.
+	Experiment *assignment `json:",omitempty"`
 }
 
 type respStats struct {
@@ -234,76 +375,354 @@ type respStats struct {
 	Users      int
 }
 
-func recommendationStory(sr *server, keys []string, limit, offset int) (recResp, error) {
-	start := time.Now()
-	s, err := sr.storyByKey(keys[0])
-	if err != nil {
-		return recResp{}, err
-	}
-	log.Printf("Finding recommendations for \"%s\"...", s.Title)
-	recStories := make(map[string]float64)
+// seedStats describes how much a seed story contributed to the
+// recommendations.
+type seedStats struct {
+	Story  *Story
+	Weight float64
+	// Users is the number of fans of the seed story.
+	Users int
+	// Favorites is the weighted number of favorites the seed's fans added.
+	Favorites float64
+}
 
-	// Fetch users first.
-	users, err := sr.usersByKeys(s.FavedBy)
+// penalizeNegative scales down the count of every candidate by the weighted
+// fraction of the negative seeds' fans that favorited it. Candidates favorited
+// by all of them, and the negative seeds themselves, are removed.
+func (s *server) penalizeNegative(recStories map[string]float64, negative []seed) error {
+	keys := make([]string, len(negative))
+	for i, seed := range negative {
+		keys[i] = seed.Key
+	}
+	negStories, err := s.storiesByKeys(keys)
 	if err != nil {
-		return recResp{}, err
+		return err
 	}
 
-	for _, user := range users {
-		for _, story := range user.FavStories {
-			recStories[story]++
+	negCounts := make(map[string]float64)
+	total := 0.0
+	for i, st := range negStories {
+		weight := negative[i].Weight
+		users, err := s.usersByKeys(st.FavedBy)
+		if err != nil {
+			return err
+		}
+		total += weight * float64(len(users))
+		for _, user := range users {
+			for _, story := range user.FavStories {
+				negCounts[story] += weight
+			}
 		}
 	}
 
-	// Remove favorites pointing to original story.
 	for _, key := range keys {
 		delete(recStories, key)
 	}
+	applyPenalty(recStories, negCounts, total)
+	return nil
+}
 
-	favorites := 0
-	for _, count := range recStories {
-		favorites += int(count)
+// applyPenalty scales down every candidate by the fraction of total negative
+// fans in negCounts that favorited it.
+func applyPenalty(recStories, negCounts map[string]float64, total float64) {
+	if total == 0 {
+		return
 	}
-
-	stories := make([]string, 0, len(recStories))
-	for st := range recStories {
-		stories = append(stories, st)
+	for story, count := range negCounts {
+		if _, ok := recStories[story]; !ok {
+			continue
+		}
+		penalty := count / total
+		if penalty >= 1 {
+			delete(recStories, story)
+			continue
+		}
+		recStories[story] *= 1 - penalty
 	}
+}
 
-	rsl := sortMap(recStories)
-	if len(rsl) != len(stories) {
-		log.Fatalf("len(rsl) = %d, len(stories) = %d", len(rsl), len(stories))
+// recommendationStory recommends the stories favorited by the fans of the
+// seeds. The ranking is cached so later pages don't recompute it.
+func recommendationStory(sr *server, name string, seeds []seed, score scorer, opts recOptions) (recResp, error) {
+	start := time.Now()
+	cacheKey := rankingKey(name, seeds, opts)
+	cacheSeeds := rankingSeeds(seeds, opts.Negative)
+	r, gens := sr.rankings.get(cacheKey, cacheSeeds)
+	cached := r != nil
+	if !cached {
+		var err error
+		r, err = sr.rankRecommendations(seeds, score, opts)
+		sr.rankings.put(cacheKey, cacheSeeds, gens, r)
+		if err != nil {
+			return recResp{}, err
+		}
 	}
-	storyCount := len(rsl)
-	if len(rsl) > (limit + offset) {
-		rsl = rsl[offset : offset+limit]
-	} else if len(rsl) > offset {
-		rsl = rsl[offset:]
-	} else {
-		rsl = nil
+
+	authors, err := sr.existingUsersByKeys(pageKeys(r.authors, authorOffset(opts), opts.Limit))
+	if err != nil {
+		return recResp{}, err
 	}
 	startStories := time.Now()
-	sOut, err := sr.storiesByKeys(rsl)
+	sOut, err := sr.pageStories(afterCursor(r.stories, r.scores, opts.After), r.scores, opts)
 	if err != nil {
 		return recResp{}, err
 	}
-	log.Printf("storiesByKeys(len = %d) took %s", len(rsl), time.Now().Sub(startStories))
-	for i, st := range sOut {
+	log.Printf("pageStories(len = %d) took %s", len(sOut), time.Now().Sub(startStories))
+	for _, st := range sOut {
 		st.annotate()
-		st.Score = float32(recStories[rsl[i]])
+		st.Score = float32(r.scores[st.key()])
+	}
+	explanations, err := sr.explainStories(sOut, r, score)
+	if err != nil {
+		return recResp{}, err
 	}
-	s.annotate()
 	resp := recResp{
 		sOut,
-		nil,
-		&s,
+		authors,
+		r.seedStory(seeds[0].Key),
+		r.stats,
+		explanations,
 		respStats{
-			storyCount,
-			favorites,
-			len(users),
+			len(r.scores),
+			r.favorites,
+			r.fans,
 		},
+		nextCursor(sOut, r.scores, opts),
+		nil,
 	}
 
-	log.Printf("recommendationStory(%q) took %s, stats %+v", s.Title, time.Now().Sub(start), resp.Stats)
+	log.Printf("recommendationStory(%q) took %s, cached %t, stats %+v", resp.Story.Title, time.Now().Sub(start), cached, resp.Stats)
 	return resp, nil
 }
+
+// coFavorites are the candidate stories of a seed set with how often the seed
+// fans favorited them.
+type coFavorites struct {
+	// counts are the weighted co-favorite counts and shared the number of seed
+	// fans that favorited each candidate.
+	counts map[string]float64
+	shared map[string]int
+	stats  []*seedStats
+	fans   int
+	// users and fanWeights are the seed fans with the sum of the weights of
+	// the seeds they favorited. They're empty if the counts were read from the
+	// neighbor index.
+	users      []*User
+	fanWeights map[string]float64
+	neighbors  map[string]*Neighbors
+}
+
+// rankRecommendations ranks all the candidates of recommendationStory.
+func (sr *server) rankRecommendations(seeds []seed, score scorer, opts recOptions) (*ranking, error) {
+	keys := make([]string, len(seeds))
+	isSeed := make(map[string]bool, len(seeds))
+	for i, seed := range seeds {
+		keys[i] = seed.Key
+		isSeed[seed.Key] = true
+	}
+	seedStories, err := sr.storiesByKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Finding recommendations for \"%s\" and %d other seeds...", seedStories[0].Title, len(seeds)-1)
+
+	neighbors, err := sr.seedNeighbors(seeds, opts)
+	if err != nil {
+		return nil, err
+	}
+	var c *coFavorites
+	if neighbors != nil {
+		c = countNeighbors(seeds, seedStories, isSeed, neighbors)
+	} else if c, err = sr.countFans(seeds, seedStories, isSeed, opts); err != nil {
+		return nil, err
+	}
+	recStories := c.counts
+
+	// Remove favorites pointing to original story.
+	for _, key := range keys {
+		delete(recStories, key)
+	}
+
+	favorites := 0
+	for _, count := range recStories {
+		favorites += int(count)
+	}
+
+	if len(opts.Negative) > 0 {
+		if err := sr.penalizeNegative(recStories, opts.Negative); err != nil {
+			return nil, err
+		}
+	}
+
+	authors, err := sr.rankAuthors(c.users, c.fanWeights, recStories)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := recStories
+	if score != nil {
+		recStories, err = sr.scoreStories(recStories, c.shared, c.fans, score)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := sr.adjustScores(recStories, opts); err != nil {
+		return nil, err
+	}
+
+	usersByKey := make(map[string]*User, len(c.users))
+	for _, user := range c.users {
+		usersByKey[user.key()] = user
+	}
+	return &ranking{
+		seeds:       seeds,
+		seedStories: seedStories,
+		stats:       c.stats,
+		usersByKey:  usersByKey,
+		neighbors:   c.neighbors,
+		fans:        c.fans,
+		favorites:   favorites,
+		counts:      counts,
+		shared:      c.shared,
+		scores:      recStories,
+		stories:     sortMap(recStories),
+		authors:     authors,
+	}, nil
+}
+
+// seedNeighbors returns the indexed neighbors of the weighted seeds, or nil if
+// the ranking has to be counted from the fans. That's the case if one of the
+// seeds isn't indexed yet, in which case it's queued, or if the favorites are
+// weighted per user.
+func (sr *server) seedNeighbors(seeds []seed, opts recOptions) (map[string]*Neighbors, error) {
+	if !*buildIndex || opts.Recency.FavoriteHalfLife != 0 || opts.IgnoreUser != "" {
+		return nil, nil
+	}
+	var keys []string
+	for _, seed := range seeds {
+		if seed.Weight != 0 {
+			keys = append(keys, seed.Key)
+		}
+	}
+	neighbors, err := sr.neighborsByKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, key := range keys {
+		if _, ok := neighbors[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return neighbors, nil
+	}
+	if err := sr.db.Update(func(txn Txn) error {
+		return markDirty(txn, missing)
+	}); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// countNeighbors sums the indexed neighbors of the seeds. Fans of several seeds
+// are counted once per seed.
+func countNeighbors(seeds []seed, seedStories []*Story, isSeed map[string]bool, neighbors map[string]*Neighbors) *coFavorites {
+	c := &coFavorites{
+		counts:    make(map[string]float64),
+		shared:    make(map[string]int),
+		neighbors: neighbors,
+	}
+	for i, seed := range seeds {
+		if seed.Weight == 0 {
+			continue
+		}
+		n := neighbors[seed.Key]
+		ss := &seedStats{
+			Story:  seedStories[i],
+			Weight: seed.Weight,
+			Users:  len(seedStories[i].FavedBy),
+		}
+		for _, neighbor := range n.Neighbors {
+			if isSeed[neighbor.Key] {
+				continue
+			}
+			count := seed.Weight * float64(neighbor.Count)
+			c.counts[neighbor.Key] += count
+			c.shared[neighbor.Key] += int(neighbor.Count)
+			ss.Favorites += count
+		}
+		c.fans += int(n.Fans)
+		c.stats = append(c.stats, ss)
+	}
+	return c
+}
+
+// countFans counts the favorites of the seeds' fans.
+func (sr *server) countFans(seeds []seed, seedStories []*Story, isSeed map[string]bool, opts recOptions) (*coFavorites, error) {
+	now := time.Now()
+	c := &coFavorites{
+		counts:     make(map[string]float64),
+		shared:     make(map[string]int),
+		fanWeights: make(map[string]float64),
+	}
+
+	// Fetch users first. Fans of several seeds are only fetched once.
+	var fanKeys []string
+	for i, st := range seedStories {
+		if seeds[i].Weight == 0 {
+			continue
+		}
+		for _, fan := range st.FavedBy {
+			if fan == opts.IgnoreUser {
+				continue
+			}
+			if _, ok := c.fanWeights[fan]; !ok {
+				fanKeys = append(fanKeys, fan)
+			}
+			c.fanWeights[fan] += seeds[i].Weight
+		}
+	}
+	users, err := sr.usersByKeys(fanKeys)
+	if err != nil {
+		return nil, err
+	}
+	c.users = users
+	c.fans = len(users)
+	usersByKey := make(map[string]*User, len(users))
+	for _, user := range users {
+		usersByKey[user.key()] = user
+		for _, story := range user.FavStories {
+			if !isSeed[story] {
+				c.shared[story]++
+			}
+		}
+	}
+
+	for i, st := range seedStories {
+		if seeds[i].Weight == 0 {
+			continue
+		}
+		ss := &seedStats{
+			Story:  st,
+			Weight: seeds[i].Weight,
+			Users:  len(st.FavedBy),
+		}
+		for _, fan := range st.FavedBy {
+			user, ok := usersByKey[fan]
+			if !ok {
+				continue
+			}
+			for _, story := range user.FavStories {
+				if isSeed[story] {
+					continue
+				}
+				weight := ss.Weight * opts.Recency.favoriteWeight(user, story, now)
+				c.counts[story] += weight
+				ss.Favorites += weight
+			}
+		}
+		c.stats = append(c.stats, ss)
+	}
+	return c, nil
+}
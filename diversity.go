@@ -0,0 +1,97 @@
+package main
+
+// diversityPool is the number of top ranked stories that are re-ranked for
+// diversity. Stories after it keep their order.
+const diversityPool = 300
+
+// diversify re-ranks the top of keys with maximal marginal relevance, picking
+// the story maximizing
+//
+//	(1 - lambda) * relevance - lambda * max similarity to the picked stories
+//
+// where relevance is the score scaled to [0, 1].
+func (s *server) diversify(keys []string, scores map[string]float64, lambda float64) ([]string, error) {
+	if lambda <= 0 || len(keys) < 2 {
+		return keys, nil
+	}
+	n := len(keys)
+	if n > diversityPool {
+		n = diversityPool
+	}
+	stories, err := s.storiesByKeys(keys[:n])
+	if err != nil {
+		return nil, err
+	}
+	fans := make([]map[string]bool, n)
+	for i, st := range stories {
+		fans[i] = make(map[string]bool, len(st.FavedBy))
+		for _, fan := range st.FavedBy {
+			fans[i][fan] = true
+		}
+	}
+
+	maxScore := scores[keys[0]]
+	minScore := scores[keys[n-1]]
+	relevance := func(i int) float64 {
+		if maxScore == minScore {
+			return 1
+		}
+		return (scores[keys[i]] - minScore) / (maxScore - minScore)
+	}
+
+	out := make([]string, 0, len(keys))
+	picked := make([]bool, n)
+	maxSim := make([]float64, n)
+	for len(out) < n {
+		best := -1
+		bestVal := 0.0
+		for i := 0; i < n; i++ {
+			if picked[i] {
+				continue
+			}
+			val := (1-lambda)*relevance(i) - lambda*maxSim[i]
+			if best < 0 || val > bestVal {
+				best = i
+				bestVal = val
+			}
+		}
+		picked[best] = true
+		out = append(out, keys[best])
+		for i := 0; i < n; i++ {
+			if picked[i] {
+				continue
+			}
+			if sim := storySimilarity(stories[best], stories[i], fans[best], fans[i]); sim > maxSim[i] {
+				maxSim[i] = sim
+			}
+		}
+	}
+	return append(out, keys[n:]...), nil
+}
+
+// storySimilarity is the average of whether the stories share a category,
+// whether they have the same author and the Jaccard similarity of their fans.
+func storySimilarity(a, b *Story, aFans, bFans map[string]bool) float64 {
+	category := 0.0
+	if a.Category != "" && a.Category == b.Category {
+		category = 1
+	}
+	author := 0.0
+	if a.AuthorId != "" && a.authorKey() == b.authorKey() {
+		author = 1
+	}
+	if len(aFans) > len(bFans) {
+		aFans, bFans = bFans, aFans
+	}
+	shared := 0
+	for fan := range aFans {
+		if bFans[fan] {
+			shared++
+		}
+	}
+	fans := 0.0
+	if union := len(aFans) + len(bFans) - shared; union > 0 {
+		fans = float64(shared) / float64(union)
+	}
+	return (category + author + fans) / 3
+}
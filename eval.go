@@ -0,0 +1,241 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	evalUsers   = flag.Int("eval_users", 100, "number of users sampled by evaluate")
+	evalHoldout = flag.Float64("eval_holdout", 0.2, "fraction of each sampled user's favorites hidden by evaluate")
+	evalK       = flag.Int("eval_k", 20, "number of recommendations evaluate scores")
+	evalMinFavs = flag.Int("eval_min_favs", 5, "minimum favorites of a user sampled by evaluate")
+	evalSeed    = flag.Int64("eval_seed", 0, "random seed of evaluate")
+)
+
+// evalCase is a sampled user with their favorites split into seeds and the
+// hidden favorites the recommenders should find.
+type evalCase struct {
+	user   string
+	seeds  []seed
+	hidden map[string]bool
+}
+
+// evalResult accumulates the metrics of a recommender.
+type evalResult struct {
+	cases       int
+	errors      int
+	precision   float64
+	recall      float64
+	ndcg        float64
+	recommended map[string]bool
+	popularity  float64
+	recs        int
+	took        time.Duration
+	// err is the first error returned by the recommender.
+	err error
+}
+
+// evalLeaky are the recommenders built from the full data, so the hidden
+// favorites leak into their results and their metrics are optimistic.
+var evalLeaky = map[string]bool{
+	"indexed": true,
+	"als":     true,
+}
+
+// cmdEvaluate samples users, hides some of their favorites and reports how
+// well each registered recommender finds them from the rest. The rows of the
+// recommenders in evalLeaky are marked.
+func (s *server) cmdEvaluate() error {
+	cases, err := s.sampleEvalCases()
+	if err != nil {
+		return err
+	}
+	if len(cases) == 0 {
+		log.Printf("No users with at least %d favorites to evaluate", *evalMinFavs)
+		return nil
+	}
+	stories, err := s.countPrefix("story:")
+	if err != nil {
+		return err
+	}
+	hiddenPopularity, err := s.meanPopularity(cases)
+	if err != nil {
+		return err
+	}
+	log.Printf("Evaluating %d users against %d stories", len(cases), stories)
+
+	names := make([]string, 0, len(recommenders))
+	for name := range recommenders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "algo\tusers\terrors\tprecision@%d\trecall@%d\tndcg@%d\tcoverage\tpopularity\ttime/user\n", *evalK, *evalK, *evalK)
+	for _, name := range names {
+		r := s.evaluate(recommenders[name], cases)
+		if r.err != nil {
+			log.Printf("%s: %d errors, first: %+v", name, r.errors, r.err)
+		}
+		row := name
+		if evalLeaky[name] {
+			row += "*"
+		}
+		n := float64(r.cases)
+		if n == 0 {
+			n = 1
+		}
+		coverage := 0.0
+		if stories > 0 {
+			coverage = float64(len(r.recommended)) / float64(stories)
+		}
+		popularity := 0.0
+		if r.recs > 0 && hiddenPopularity > 0 {
+			popularity = r.popularity / float64(r.recs) / hiddenPopularity
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.4f\t%.4f\t%.4f\t%.4f\t%.2f\t%s\n",
+			row, r.cases, r.errors, r.precision/n, r.recall/n, r.ndcg/n,
+			coverage, popularity,
+			r.took/time.Duration(len(cases)))
+	}
+	fmt.Fprintln(w, "popularity is the mean popularity of the recommendations relative to the hidden favorites.")
+	fmt.Fprintln(w, "* built from the full data including the hidden favorites, so the metrics are optimistic.")
+	return w.Flush()
+}
+
+func (s *server) evaluate(rec Recommender, cases []evalCase) evalResult {
+	r := evalResult{
+		recommended: make(map[string]bool),
+	}
+	start := time.Now()
+	for _, c := range cases {
+		resp, err := rec.Recommend(s, c.seeds, recOptions{
+			Limit:      *evalK,
+			IgnoreUser: c.user,
+		})
+		if err != nil {
+			if r.err == nil {
+				r.err = err
+			}
+			r.errors++
+			continue
+		}
+		r.cases++
+		hits := 0
+		dcg := 0.0
+		for i, st := range resp.Stories {
+			key := st.key()
+			r.recommended[key] = true
+			r.popularity += float64(popularity(st))
+			r.recs++
+			if c.hidden[key] {
+				hits++
+				dcg += 1 / math.Log2(float64(i+2))
+			}
+		}
+		idcg := 0.0
+		for i := 0; i < len(c.hidden) && i < *evalK; i++ {
+			idcg += 1 / math.Log2(float64(i+2))
+		}
+		r.precision += float64(hits) / float64(*evalK)
+		r.recall += float64(hits) / float64(len(c.hidden))
+		if idcg > 0 {
+			r.ndcg += dcg / idcg
+		}
+	}
+	r.took = time.Since(start)
+	return r
+}
+
+// sampleEvalCases reservoir samples *evalUsers users with at least
+// *evalMinFavs favorites.
+func (s *server) sampleEvalCases() ([]evalCase, error) {
+	r := rand.New(rand.NewSource(*evalSeed))
+	var sample []*User
+	seen := 0
+	if err := s.db.View(func(txn Txn) error {
+		return txn.Scan("user:", true, func(_ string, body []byte) error {
+			u := &User{}
+			if err := u.Unmarshal(body); err != nil {
+				return err
+			}
+			if len(u.FavStories) < *evalMinFavs {
+				return nil
+			}
+			seen++
+			if len(sample) < *evalUsers {
+				sample = append(sample, u)
+			} else if i := r.Intn(seen); i < *evalUsers {
+				sample[i] = u
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	var cases []evalCase
+	for _, u := range sample {
+		favs, err := s.existingKeys(u.FavStories)
+		if err != nil {
+			return nil, err
+		}
+		r.Shuffle(len(favs), func(i, j int) { favs[i], favs[j] = favs[j], favs[i] })
+		n := int(math.Ceil(float64(len(favs)) * *evalHoldout))
+		if n == 0 || n >= len(favs) {
+			continue
+		}
+		c := evalCase{
+			user:   u.key(),
+			hidden: make(map[string]bool, n),
+		}
+		for _, key := range favs[:n] {
+			c.hidden[key] = true
+		}
+		for _, key := range favs[n:] {
+			c.seeds = append(c.seeds, seed{key, 1})
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// meanPopularity returns the mean popularity of the hidden favorites.
+func (s *server) meanPopularity(cases []evalCase) (float64, error) {
+	var keys []string
+	for _, c := range cases {
+		for key := range c.hidden {
+			keys = append(keys, key)
+		}
+	}
+	total := 0
+	if err := s.forStories(keys, func(key string, st *Story) {
+		total += popularity(st)
+	}); err != nil {
+		return 0, err
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	return float64(total) / float64(len(keys)), nil
+}
+
+// countPrefix returns the number of keys starting with prefix.
+func (s *server) countPrefix(prefix string) (int, error) {
+	count := 0
+	err := s.db.View(func(txn Txn) error {
+		return txn.Scan(prefix, false, func(string, []byte) error {
+			count++
+			return nil
+		})
+	})
+	return count, err
+}
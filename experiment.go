@@ -0,0 +1,305 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var experimentsPath = flag.String("experiments", "", "JSON file of recommendation experiments")
+
+// experiment splits callers between recommendation variants.
+//
+// An experiments file looks like:
+//
+//	[{"Name": "jaccard", "Variants": [
+//	  {"Name": "control", "Algo": "cooccurrence", "Weight": 1},
+//	  {"Name": "jaccard", "Algo": "jaccard", "Weight": 1}
+//	]}]
+type experiment struct {
+	Name     string
+	Variants []*variant
+}
+
+// variant is a recommendation strategy tried by an experiment.
+type variant struct {
+	Name   string
+	Algo   string
+	Weight int
+	// Diversity and Novelty override the request's values if set.
+	Diversity *float64
+	Novelty   *float64
+}
+
+// assignment is the variant a caller was bucketed into.
+type assignment struct {
+	Experiment string
+	Variant    string
+	Client     string
+}
+
+// Events are recorded as exp:<experiment>|<variant>|<kind>|<unix nanos>|<client>
+// with the number of stories shown as the value of impressions and the story
+// key as the value of clicks.
+const (
+	experimentPrefix = "exp:"
+	eventImpression  = "imp"
+	eventClick       = "click"
+	clientCookie     = "ficrecommend_client"
+)
+
+func loadExperiments(path string) ([]*experiment, error) {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var exps []*experiment
+	if err := json.Unmarshal(body, &exps); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", path)
+	}
+	// Callers are only bucketed into one experiment at a time.
+	if len(exps) > 1 {
+		return nil, errors.Errorf("%s has %d experiments, only one can run at a time", path, len(exps))
+	}
+	for _, e := range exps {
+		if strings.Contains(e.Name, "|") {
+			return nil, errors.Errorf("experiment %q: name can't contain |", e.Name)
+		}
+		if len(e.Variants) == 0 {
+			return nil, errors.Errorf("experiment %q has no variants", e.Name)
+		}
+		for _, v := range e.Variants {
+			if strings.Contains(v.Name, "|") {
+				return nil, errors.Errorf("experiment %q variant %q: name can't contain |", e.Name, v.Name)
+			}
+			if _, ok := recommenders[v.Algo]; !ok {
+				return nil, errors.Errorf("experiment %q variant %q: unknown algorithm %q", e.Name, v.Name, v.Algo)
+			}
+			if v.Weight <= 0 {
+				return nil, errors.Errorf("experiment %q variant %q: weight must be > 0", e.Name, v.Name)
+			}
+		}
+	}
+	return exps, nil
+}
+
+// clientID returns the caller's client id from the client parameter or
+// cookie, setting a new cookie if there's neither.
+func clientID(w http.ResponseWriter, r *http.Request) (string, error) {
+	if id := r.FormValue("client"); id != "" {
+		return id, nil
+	}
+	if c, err := r.Cookie(clientCookie); err == nil && c.Value != "" {
+		return c.Value, nil
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(buf)
+	http.SetCookie(w, &http.Cookie{
+		Name:    clientCookie,
+		Value:   id,
+		Path:    "/",
+		Expires: time.Now().Add(365 * 24 * time.Hour),
+	})
+	return id, nil
+}
+
+// bucket deterministically picks the client's variant of the experiment.
+func (e *experiment) bucket(client string) *variant {
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	h := fnv.New32a()
+	h.Write([]byte(e.Name + "|" + client))
+	n := int(h.Sum32() % uint32(total))
+	for _, v := range e.Variants {
+		n -= v.Weight
+		if n < 0 {
+			return v
+		}
+	}
+	return e.Variants[len(e.Variants)-1]
+}
+
+// assignVariant buckets the caller into the running experiment and applies the
+// variant to algo and opts. It returns nil if no experiment is running.
+func (s *server) assignVariant(w http.ResponseWriter, r *http.Request, algo *string, opts *recOptions) (*assignment, error) {
+	if len(s.experiments) == 0 {
+		return nil, nil
+	}
+	client, err := clientID(w, r)
+	if err != nil {
+		return nil, err
+	}
+	e := s.experiments[0]
+	v := e.bucket(client)
+	*algo = v.Algo
+	if v.Diversity != nil {
+		opts.Diversity = *v.Diversity
+	}
+	if v.Novelty != nil {
+		opts.Novelty = *v.Novelty
+	}
+	return &assignment{
+		Experiment: e.Name,
+		Variant:    v.Name,
+		Client:     client,
+	}, nil
+}
+
+// hasVariant returns whether the variant belongs to a running experiment.
+func (s *server) hasVariant(experiment, variant string) bool {
+	for _, e := range s.experiments {
+		if e.Name != experiment {
+			continue
+		}
+		for _, v := range e.Variants {
+			if v.Name == variant {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func eventKey(a assignment, kind string) string {
+	return fmt.Sprintf("%s%s|%s|%s|%d|%s", experimentPrefix, a.Experiment, a.Variant, kind, time.Now().UnixNano(), a.Client)
+}
+
+func (s *server) recordEvent(a assignment, kind, value string) error {
+	return s.db.Update(func(txn Txn) error {
+		return txn.Set(eventKey(a, kind), []byte(value))
+	})
+}
+
+func (s *server) handleClick(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	a := assignment{
+		Experiment: r.FormValue("experiment"),
+		Variant:    r.FormValue("variant"),
+		Client:     r.FormValue("client"),
+	}
+	story := r.FormValue("story")
+	if a.Experiment == "" || a.Variant == "" || story == "" {
+		http.Error(w, "experiment, variant and story are required", 400)
+		return
+	}
+	if !s.hasVariant(a.Experiment, a.Variant) {
+		http.Error(w, fmt.Sprintf("unknown experiment variant: %q %q", a.Experiment, a.Variant), 400)
+		return
+	}
+	if a.Client == "" {
+		if c, err := r.Cookie(clientCookie); err == nil {
+			a.Client = c.Value
+		}
+	}
+	if err := s.recordEvent(a, eventClick, story); err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// variantStats are the click-through stats of an experiment variant.
+type variantStats struct {
+	Experiment  string
+	Variant     string
+	Clients     int
+	Impressions int
+	Clicks      int
+	CTR         float64
+}
+
+// experimentStats sums the recorded events of every variant.
+func (s *server) experimentStats() ([]*variantStats, error) {
+	stats := make(map[string]*variantStats)
+	clients := make(map[string]map[string]bool)
+	if err := s.db.View(func(txn Txn) error {
+		return txn.Scan(experimentPrefix, true, func(key string, val []byte) error {
+			// The client id is last since it can contain |.
+			parts := strings.SplitN(key[len(experimentPrefix):], "|", 5)
+			if len(parts) != 5 {
+				return nil
+			}
+			id := parts[0] + "|" + parts[1]
+			vs, ok := stats[id]
+			if !ok {
+				vs = &variantStats{Experiment: parts[0], Variant: parts[1]}
+				stats[id] = vs
+				clients[id] = make(map[string]bool)
+			}
+			clients[id][parts[4]] = true
+			switch parts[2] {
+			case eventImpression:
+				n, _ := strconv.Atoi(string(val))
+				vs.Impressions += n
+			case eventClick:
+				vs.Clicks++
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	out := make([]*variantStats, 0, len(stats))
+	for id, vs := range stats {
+		vs.Clients = len(clients[id])
+		if vs.Impressions > 0 {
+			vs.CTR = float64(vs.Clicks) / float64(vs.Impressions)
+		}
+		out = append(out, vs)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Experiment != out[j].Experiment {
+			return out[i].Experiment < out[j].Experiment
+		}
+		return out[i].Variant < out[j].Variant
+	})
+	return out, nil
+}
+
+func (s *server) handleExperiments(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	stats, err := s.experimentStats()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		http.Error(w, err.Error(), 500)
+	}
+}
+
+// cmdExperimentReport prints the click-through stats of every variant.
+func (s *server) cmdExperimentReport() error {
+	stats, err := s.experimentStats()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "experiment\tvariant\tclients\timpressions\tclicks\tctr")
+	for _, vs := range stats {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%.4f\n", vs.Experiment, vs.Variant, vs.Clients, vs.Impressions, vs.Clicks, vs.CTR)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,105 @@
+package main
+
+// explainSampleSize is the number of fans sampled for each seed.
+const explainSampleSize = 5
+
+// explanation describes why a story was recommended.
+type explanation struct {
+	Seeds []*seedExplanation
+	// Score is the breakdown of the normalized score, if any.
+	Score *scoreBreakdown `json:",omitempty"`
+}
+
+// seedExplanation describes the fans a recommended story shares with a seed,
+// e.g. "12 of the 40 people who favorited X also favorited this".
+type seedExplanation struct {
+	Key        string
+	Title      string
+	SharedFans int
+	Fans       int
+	Sample     []fanSample
+}
+
+type fanSample struct {
+	Key  string
+	Name string
+}
+
+// scoreBreakdown are the inputs and output of the scorer. Score is the
+// scorer's output scaled by Weight, the mean weight of the shared fans'
+// favorites.
+type scoreBreakdown struct {
+	cooc
+	Weight float64
+	Score  float64
+}
+
+// explainStories explains each of the recommended stories of the ranking from
+// the seed fans that favorited them. Rankings read from the neighbor index only
+// have the number of shared fans and no samples.
+func (s *server) explainStories(stories []*Story, r *ranking, score scorer) ([]*explanation, error) {
+	out := make([]*explanation, len(stories))
+	index := make(map[string]int, len(stories))
+	keys := make([]string, len(stories))
+	for i, st := range stories {
+		keys[i] = st.key()
+		index[keys[i]] = i
+		out[i] = &explanation{}
+	}
+
+	for i, seedStory := range r.seedStories {
+		if r.seeds[i].Weight == 0 {
+			continue
+		}
+		seedExps := make([]*seedExplanation, len(stories))
+		for j := range stories {
+			seedExps[j] = &seedExplanation{
+				Key:   seedStory.key(),
+				Title: seedStory.Title,
+				Fans:  len(seedStory.FavedBy),
+			}
+		}
+		if n, ok := r.neighbors[seedStory.key()]; ok {
+			for _, neighbor := range n.Neighbors {
+				if j, ok := index[neighbor.Key]; ok {
+					seedExps[j].SharedFans = int(neighbor.Count)
+				}
+			}
+		}
+		for _, fan := range seedStory.FavedBy {
+			user, ok := r.usersByKey[fan]
+			if !ok {
+				continue
+			}
+			for _, story := range user.FavStories {
+				j, ok := index[story]
+				if !ok {
+					continue
+				}
+				e := seedExps[j]
+				e.SharedFans++
+				if len(e.Sample) < explainSampleSize {
+					e.Sample = append(e.Sample, fanSample{fan, user.Name})
+				}
+			}
+		}
+		for j, e := range seedExps {
+			if e.SharedFans > 0 {
+				out[j].Seeds = append(out[j].Seeds, e)
+			}
+		}
+	}
+
+	if score != nil {
+		coocs, err := s.coocs(r.shared, keys, r.fans)
+		if err != nil {
+			return nil, err
+		}
+		for i, key := range keys {
+			c := coocs[key]
+			weight := meanWeight(r.counts[key], r.shared[key])
+			out[i].Score = &scoreBreakdown{c, weight, score(c) * weight}
+		}
+	}
+	return out, nil
+}
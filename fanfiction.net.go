@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/valyala/fasthttp"
@@ -15,24 +16,25 @@ import (
 
 func init() {
 	scrapers = append(scrapers, scrapeFFnet, scrapeFictionPress)
-	recommenders = append(recommenders, recommendFFnet, recommendFictionPress)
+	storyURLs = append(storyURLs,
+		storyURL{ffnetRegex, FFNET},
+		storyURL{fictionPressRegex, FICTIONPRESS},
+	)
+	userURLs = append(userURLs,
+		userURL{ffnetUserRegex, FFNET, false},
+		userURL{fictionPressUserRegex, FICTIONPRESS, false},
+	)
 }
 
 var ffnetRegex = regexp.MustCompile(`^https?:\/\/.*fanfiction\.net\/s\/(\d+).*$`)
 var fictionPressRegex = regexp.MustCompile(`^https?:\/\/.*fictionpress\.com\/s\/(\d+).*$`)
-
-func recommendFFnet(s *server, urls []string, limit, offset int) (recResp, error) {
-	return recommendGeneric(s, urls, limit, offset, ffnetRegex, FFNET)
-}
+var ffnetUserRegex = regexp.MustCompile(`^https?:\/\/.*fanfiction\.net\/u\/(\d+).*$`)
+var fictionPressUserRegex = regexp.MustCompile(`^https?:\/\/.*fictionpress\.com\/u\/(\d+).*$`)
 
 func scrapeFFnet(s *server) {
 	scrapeFFGroup(s, "www.fanfiction.net", FFNET, 8043930)
 }
 
-func recommendFictionPress(s *server, urls []string, limit, offset int) (recResp, error) {
-	return recommendGeneric(s, urls, limit, offset, fictionPressRegex, FICTIONPRESS)
-}
-
 func scrapeFictionPress(s *server) {
 	scrapeFFGroup(s, "www.fictionpress.com", FICTIONPRESS, 1067244)
 }
@@ -112,6 +114,7 @@ func (u *User) fetch(doc *goquery.Document, sr *server, site Site) error {
 	u.Exists = true
 	u.Name = strings.TrimSpace(doc.Find("#content_wrapper_inner span").First().Text())
 
+	now := time.Now().Unix()
 	var stories []Story
 	for _, typ := range []string{".favstories", ".mystories"} {
 		stories = stories[:0]
@@ -131,6 +134,13 @@ func (u *User) fetch(doc *goquery.Document, sr *server, site Site) error {
 			st.Chapters = atoi(s.AttrOr("data-chapters", ""))
 			st.Complete = s.AttrOr("data-statusid", "") == "2"
 			st.Image = s.Find("img").AttrOr("data-original", "")
+			// Favorites link to their author's profile, /u/<id>/<name>.
+			if author := s.Find(`a[href^="/u/"]`).First(); author.Length() > 0 {
+				if bits := strings.Split(author.AttrOr("href", ""), "/"); len(bits) >= 3 {
+					st.AuthorId = bits[2]
+					st.AuthorName = strings.TrimSpace(author.Text())
+				}
+			}
 
 			contentDiv := s.Find("div").First()
 			html, _ := contentDiv.Html()
@@ -143,23 +153,22 @@ func (u *User) fetch(doc *goquery.Document, sr *server, site Site) error {
 			switch typ {
 			case ".favstories":
 				u.FavStories = append(u.FavStories, string(st.key()))
+				u.markSeen(st.key(), now)
 			case ".mystories":
+				st.AuthorId = u.Id
+				st.AuthorName = u.Name
 				u.Stories = append(u.Stories, string(st.key()))
 			}
 			stories = append(stories, st)
 		})
 		for _, st := range stories {
-			if st.checkExistsTitle(sr) {
-				st2, err := sr.storyByKey(st.key())
-				if err != nil {
+			if typ == ".mystories" {
+				if err := sr.saveTwin(st.Title, u.Name, u.key(), st.key()); err != nil {
 					return err
 				}
-				st.FavedBy = st2.FavedBy
-			}
-			if !strContains(st.FavedBy, u.key()) {
-				st.FavedBy = append(st.FavedBy, u.key())
 			}
-			if err := st.save(sr); err != nil {
+			st.FavedBy = []string{u.key()}
+			if err := sr.mergeStory(st); err != nil {
 				return err
 			}
 		}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// storyFilter restricts recommendations by story metadata. Zero values don't
+// filter.
+type storyFilter struct {
+	// Category matches stories whose category contains it, ignoring case.
+	Category     string
+	MinWords     int32
+	MaxWords     int32
+	Complete     *bool
+	UpdatedSince int32
+	MinFavorites int32
+	MaxFavorites int32
+	MinChapters  int32
+	Sites        []Site
+}
+
+func (f storyFilter) empty() bool {
+	return f.Category == "" && f.MinWords == 0 && f.MaxWords == 0 &&
+		f.Complete == nil && f.UpdatedSince == 0 && f.MinFavorites == 0 &&
+		f.MaxFavorites == 0 && f.MinChapters == 0 && len(f.Sites) == 0
+}
+
+// matchKey reports whether a story key can match the filter without loading
+// the story.
+func (f storyFilter) matchKey(key string) bool {
+	if len(f.Sites) == 0 {
+		return true
+	}
+	for _, site := range f.Sites {
+		if strings.HasPrefix(key, "story:"+Site_name[int32(site)]+":") {
+			return true
+		}
+	}
+	return false
+}
+
+func (f storyFilter) match(st *Story) bool {
+	if f.Category != "" && !strings.Contains(strings.ToLower(st.Category), strings.ToLower(f.Category)) {
+		return false
+	}
+	if f.MinWords > 0 && st.WordCount < f.MinWords {
+		return false
+	}
+	if f.MaxWords > 0 && st.WordCount > f.MaxWords {
+		return false
+	}
+	if f.Complete != nil && st.Complete != *f.Complete {
+		return false
+	}
+	if f.UpdatedSince > 0 && st.DateUpdate < f.UpdatedSince {
+		return false
+	}
+	if f.MinFavorites > 0 && st.Favorites < f.MinFavorites {
+		return false
+	}
+	if f.MaxFavorites > 0 && st.Favorites > f.MaxFavorites {
+		return false
+	}
+	if f.MinChapters > 0 && st.Chapters < f.MinChapters {
+		return false
+	}
+	return true
+}
+
+// parseStoryFilter reads the filter query parameters.
+func parseStoryFilter(r *http.Request) (storyFilter, error) {
+	f := storyFilter{
+		Category:     r.FormValue("category"),
+		MinWords:     int32(requestFormInt(r, "min_words", 0)),
+		MaxWords:     int32(requestFormInt(r, "max_words", 0)),
+		MinFavorites: int32(requestFormInt(r, "min_favorites", 0)),
+		MaxFavorites: int32(requestFormInt(r, "max_favorites", 0)),
+		MinChapters:  int32(requestFormInt(r, "min_chapters", 0)),
+	}
+	if val := r.FormValue("complete"); val != "" {
+		complete, err := strconv.ParseBool(val)
+		if err != nil {
+			return storyFilter{}, errors.Errorf("invalid complete: %q", val)
+		}
+		f.Complete = &complete
+	}
+	if val := r.FormValue("updated_since"); val != "" {
+		since, err := parseDate(val)
+		if err != nil {
+			return storyFilter{}, err
+		}
+		f.UpdatedSince = since
+	}
+	if val := r.FormValue("site"); val != "" {
+		for _, name := range strings.Split(val, "|") {
+			site, ok := Site_value[strings.ToUpper(name)]
+			if !ok {
+				return storyFilter{}, errors.Errorf("unknown site: %q", name)
+			}
+			f.Sites = append(f.Sites, Site(site))
+		}
+	}
+	return f, nil
+}
+
+// parseDate parses either a unix timestamp or a YYYY-MM-DD date.
+func parseDate(val string) (int32, error) {
+	if ts, err := strconv.Atoi(val); err == nil {
+		return int32(ts), nil
+	}
+	t, err := time.Parse("2006-01-02", val)
+	if err != nil {
+		return 0, errors.Errorf("invalid date: %q", val)
+	}
+	return int32(t.Unix()), nil
+}
+
+const filterBatchSize = 500
+
+// pageKeys returns the limit keys after offset.
+func pageKeys(keys []string, offset, limit int) []string {
+	if len(keys) > offset+limit {
+		return keys[offset : offset+limit]
+	} else if len(keys) > offset {
+		return keys[offset:]
+	}
+	return nil
+}
+
+// pageStories loads the page of stories selected by opts from the keys ranked
+// by scores. The ranking is diversified by opts.Diversity, and stories that
+// don't match opts.Filter are skipped before paginating.
+func (s *server) pageStories(keys []string, scores map[string]float64, opts recOptions) ([]*Story, error) {
+	keys, err := s.diversify(keys, scores, opts.Diversity)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Filter.empty() {
+		return s.storiesByKeys(pageKeys(keys, opts.Offset, opts.Limit))
+	}
+
+	var out []*Story
+	skipped := 0
+	for start := 0; start < len(keys) && len(out) < opts.Limit; start += filterBatchSize {
+		end := start + filterBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		var batch []string
+		for _, key := range keys[start:end] {
+			if opts.Filter.matchKey(key) {
+				batch = append(batch, key)
+			}
+		}
+		stories, err := s.storiesByKeys(batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range stories {
+			if !opts.Filter.match(st) {
+				continue
+			}
+			if skipped < opts.Offset {
+				skipped++
+				continue
+			}
+			out = append(out, st)
+			if len(out) >= opts.Limit {
+				break
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strconv"
+	"time"
+)
+
+var (
+	buildIndex = flag.Bool("index", true, "whether to maintain and recommend from the neighbor index")
+	indexSize  = flag.Int("indexsize", 200, "number of neighbors to keep per story")
+)
+
+// The neighbor index keeps the top co-favorited stories of each story under
+// neighborsPrefix. Saving a user marks all of their favorite stories dirty
+// under dirtyPrefix and the indexer recomputes them in the background.
+// rankRecommendations reads the index when all of the seeds are indexed.
+const (
+	neighborsPrefix = "neighbors:"
+	dirtyPrefix     = "dirty:"
+	indexInterval   = time.Minute
+	indexBatchSize  = 1000
+)
+
+func neighborsKey(story string) string {
+	return neighborsPrefix + story
+}
+
+// markDirty queues the stories to have their neighbors recomputed.
+func markDirty(txn Txn, stories []string) error {
+	now := []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+	for _, story := range stories {
+		if err := txn.Set(dirtyPrefix+story, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *server) startIndexing() {
+	log.Print("starting indexing...")
+	go func() {
+		for {
+			start := time.Now()
+			n, err := s.updateIndex()
+			if err != nil {
+				log.Printf("updateIndex: %+v", err)
+			}
+			if n > 0 {
+				log.Printf("Indexed %d stories in %s", n, time.Since(start))
+			}
+			if n < indexBatchSize {
+				time.Sleep(indexInterval)
+			}
+		}
+	}()
+}
+
+// updateIndex recomputes up to indexBatchSize dirty stories and returns the
+// number recomputed.
+func (s *server) updateIndex() (int, error) {
+	dirty := make(map[string]string)
+	if err := s.db.View(func(txn Txn) error {
+		return txn.Scan(dirtyPrefix, true, func(key string, val []byte) error {
+			if len(dirty) >= indexBatchSize {
+				return errStopScan
+			}
+			dirty[key[len(dirtyPrefix):]] = string(val)
+			return nil
+		})
+	}); err != nil {
+		return 0, err
+	}
+
+	for story, marked := range dirty {
+		n, err := s.computeNeighbors(story)
+		if err != nil {
+			return 0, err
+		}
+		body, err := n.Marshal()
+		if err != nil {
+			return 0, err
+		}
+		if err := s.db.Update(func(txn Txn) error {
+			if err := txn.Set(neighborsKey(story), body); err != nil {
+				return err
+			}
+			// Leave the marker if the story was marked again since.
+			val, err := txn.Get(dirtyPrefix + story)
+			if err != nil {
+				return err
+			}
+			if string(val) != marked {
+				return nil
+			}
+			return txn.Delete(dirtyPrefix + story)
+		}); err != nil && err != errConflict {
+			return 0, err
+		}
+	}
+	return len(dirty), nil
+}
+
+// computeNeighbors counts the stories favorited by the fans of story and
+// returns the top *indexSize.
+func (s *server) computeNeighbors(story string) (Neighbors, error) {
+	st, err := s.storyByKey(story)
+	if err == errNotFound {
+		return Neighbors{Updated: time.Now().Unix()}, nil
+	} else if err != nil {
+		return Neighbors{}, err
+	}
+	users, err := s.existingUsersByKeys(st.FavedBy)
+	if err != nil {
+		return Neighbors{}, err
+	}
+	counts := make(map[string]float64)
+	for _, user := range users {
+		for _, fav := range user.FavStories {
+			counts[fav]++
+		}
+	}
+	delete(counts, story)
+
+	keys := sortMap(counts)
+	if len(keys) > *indexSize {
+		keys = keys[:*indexSize]
+	}
+	n := Neighbors{
+		Neighbors: make([]*Neighbor, len(keys)),
+		Fans:      int32(len(users)),
+		Updated:   time.Now().Unix(),
+	}
+	for i, key := range keys {
+		n.Neighbors[i] = &Neighbor{
+			Key:   key,
+			Count: float32(counts[key]),
+		}
+	}
+	return n, nil
+}
+
+// neighborsByKeys returns the indexed neighbors of the stories. Stories that
+// haven't been indexed yet are missing from the result.
+func (s *server) neighborsByKeys(stories []string) (map[string]*Neighbors, error) {
+	out := make(map[string]*Neighbors, len(stories))
+	if err := s.db.View(func(txn Txn) error {
+		for _, story := range stories {
+			body, err := txn.Get(neighborsKey(story))
+			if err == errNotFound {
+				continue
+			} else if err != nil {
+				return err
+			}
+			n := &Neighbors{}
+			if err := n.Unmarshal(body); err != nil {
+				return err
+			}
+			out[story] = n
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func init() {
+	registerRecommender(indexedRecommender{})
+}
+
+// indexedRecommender ranks stories by their co-favorite counts read from the
+// neighbor index. Requests with seeds that aren't indexed yet fall back to
+// counting from the fans.
+type indexedRecommender struct{}
+
+func (indexedRecommender) Name() string { return "indexed" }
+
+func (indexedRecommender) Match(s *server, urls []string) ([]seed, error) {
+	return matchStoryURLs(s, urls)
+}
+
+func (indexedRecommender) Recommend(s *server, seeds []seed, opts recOptions) (recResp, error) {
+	start := time.Now()
+	keys := make([]string, len(seeds))
+	isSeed := make(map[string]bool, len(seeds))
+	for i, seed := range seeds {
+		keys[i] = seed.Key
+		isSeed[seed.Key] = true
+	}
+	neighbors, err := s.neighborsByKeys(keys)
+	if err != nil {
+		return recResp{}, err
+	}
+	var missing []string
+	for _, seed := range seeds {
+		if _, ok := neighbors[seed.Key]; !ok && seed.Weight > 0 {
+			missing = append(missing, seed.Key)
+		}
+	}
+	if len(missing) > 0 {
+		if err := s.db.Update(func(txn Txn) error {
+			return markDirty(txn, missing)
+		}); err != nil {
+			return recResp{}, err
+		}
+		return recommendationStory(s, "cooccurrence", seeds, nil, opts)
+	}
+
+	seedStories, err := s.storiesByKeys(keys)
+	if err != nil {
+		return recResp{}, err
+	}
+	recStories := make(map[string]float64)
+	var stats []*seedStats
+	users := 0
+	for i, seed := range seeds {
+		if seed.Weight == 0 {
+			continue
+		}
+		n := neighbors[seed.Key]
+		ss := &seedStats{
+			Story:  seedStories[i],
+			Weight: seed.Weight,
+			Users:  int(n.Fans),
+		}
+		for _, neighbor := range n.Neighbors {
+			if isSeed[neighbor.Key] {
+				continue
+			}
+			count := seed.Weight * float64(neighbor.Count)
+			recStories[neighbor.Key] += count
+			ss.Favorites += count
+		}
+		users += int(n.Fans)
+		stats = append(stats, ss)
+	}
+
+	favorites := 0
+	for _, count := range recStories {
+		favorites += int(count)
+	}
+
+	if len(opts.Negative) > 0 {
+		negKeys := make([]string, len(opts.Negative))
+		for i, seed := range opts.Negative {
+			negKeys[i] = seed.Key
+		}
+		negNeighbors, err := s.neighborsByKeys(negKeys)
+		if err != nil {
+			return recResp{}, err
+		}
+		negCounts := make(map[string]float64)
+		total := 0.0
+		for _, seed := range opts.Negative {
+			n, ok := negNeighbors[seed.Key]
+			if !ok {
+				continue
+			}
+			total += seed.Weight * float64(n.Fans)
+			for _, neighbor := range n.Neighbors {
+				negCounts[neighbor.Key] += seed.Weight * float64(neighbor.Count)
+			}
+		}
+		for _, key := range negKeys {
+			delete(recStories, key)
+		}
+		applyPenalty(recStories, negCounts, total)
+	}
+
+	rsl, err := s.rankStories(recStories, opts)
+	if err != nil {
+		return recResp{}, err
+	}
+	sOut, err := s.pageStories(rsl, recStories, opts)
+	if err != nil {
+		return recResp{}, err
+	}
+	for _, st := range sOut {
+		st.annotate()
+		st.Score = float32(recStories[st.key()])
+	}
+	resp := recResp{
+		Stories: sOut,
+		Story:   seedStories[0],
+		Seeds:   stats,
+		Cursor:  nextCursor(sOut, recStories, opts),
+		Stats: respStats{
+			StoryCount: len(recStories),
+			Favorites:  favorites,
+			Users:      users,
+		},
+	}
+	log.Printf("indexedRecommender(%q) took %s, stats %+v", seedStories[0].Title, time.Since(start), resp.Stats)
+	return resp, nil
+}
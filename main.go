@@ -11,10 +11,11 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	_ "net/http/pprof"
 
-	"github.com/dgraph-io/badger"
 	"github.com/pkg/errors"
 )
 
@@ -66,7 +67,16 @@ type storySlice struct {
 
 func (a *storySlice) Len() int           { return len(a.arr) }
 func (a *storySlice) Swap(i, j int)      { a.arr[i], a.arr[j] = a.arr[j], a.arr[i] }
-func (a *storySlice) Less(i, j int) bool { return a.m[a.arr[i]] > a.m[a.arr[j]] }
+func (a *storySlice) Less(i, j int) bool { return ranksBefore(a.m, a.arr[i], a.arr[j]) }
+
+// ranksBefore orders items by descending score, breaking ties by key so the
+// order is deterministic.
+func ranksBefore(m map[string]float64, a, b string) bool {
+	if m[a] != m[b] {
+		return m[a] > m[b]
+	}
+	return a < b
+}
 
 // sortMap returns the items in a map.
 func sortMap(m map[string]float64) []string {
@@ -81,26 +91,62 @@ func sortMap(m map[string]float64) []string {
 	return ss.arr
 }
 
-var recommenders []func(s *server, urls []string, limit, offset int) (recResp, error)
-
-func (s *server) recommendations(url string, limit, offset int) (recResp, error) {
-	urls := strings.Split(url, "|")
-	for _, rec := range recommenders {
-		resp, err := rec(s, urls, limit, offset)
-		if err == errStoryNotFound {
+// topK returns the first k items of sortMap(m) that come after the cursor, if
+// any, without sorting the whole map. k < 0 returns all of them.
+func topK(m map[string]float64, k int, after *cursor) []string {
+	if k < 0 && after == nil {
+		return sortMap(m)
+	}
+	// The heap's root is the item ranked last.
+	h := &storySlice{m: m}
+	less := func(i, j int) bool { return ranksBefore(m, h.arr[j], h.arr[i]) }
+	for key, score := range m {
+		if after != nil && !after.before(score, key) {
 			continue
-		} else if err != nil {
-			return recResp{}, err
 		}
-		return resp, nil
+		if k < 0 || len(h.arr) < k {
+			h.arr = append(h.arr, key)
+			heapUp(h, less, len(h.arr)-1)
+		} else if k > 0 && ranksBefore(m, key, h.arr[0]) {
+			h.arr[0] = key
+			heapDown(h, less, 0)
+		}
+	}
+	sort.Sort(h)
+	return h.arr
+}
+
+func heapUp(h *storySlice, less func(i, j int) bool, i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !less(i, parent) {
+			return
+		}
+		h.Swap(i, parent)
+		i = parent
+	}
+}
+
+func heapDown(h *storySlice, less func(i, j int) bool, i int) {
+	for {
+		smallest := i
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child < len(h.arr) && less(child, smallest) {
+				smallest = child
+			}
+		}
+		if smallest == i {
+			return
+		}
+		h.Swap(i, smallest)
+		i = smallest
 	}
-	return recResp{}, errStoryNotFound
 }
 
 var scrapers []func(s *server)
 
-func cmdRecommend(s *server, id string) {
-	recs, err := s.recommendations(id, 20, 0)
+func cmdRecommend(s *server, id, algo string) {
+	recs, err := s.recommendations(id, "", algo, recOptions{Limit: 20})
 	if err != nil {
 		log.Print(err)
 		return
@@ -131,10 +177,19 @@ func requestFormInt(r *http.Request, field string, def int) int {
 	return num
 }
 
+// hiddenGemsMaxFavorites is the favorites ceiling of the hidden gems mode.
+const hiddenGemsMaxFavorites = 1000
+
 func (s *server) handleRecommendation(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	id := r.FormValue("id")
+	neg := r.FormValue("neg")
+	algo := r.FormValue("algo")
+	if _, ok := recommenders[algo]; algo != "" && !ok {
+		http.Error(w, fmt.Sprintf("unknown recommendation algorithm: %q", algo), 400)
+		return
+	}
 	limit := requestFormInt(r, "limit", 100)
 	offset := requestFormInt(r, "offset", 0)
 	if limit > 200 || limit < 0 {
@@ -145,14 +200,112 @@ func (s *server) handleRecommendation(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "offset must be  >= 0", 400)
 		return
 	}
-	resp, err := s.recommendations(id, limit, offset)
+	diversity := 0.0
+	if val := r.FormValue("diversity"); val != "" {
+		var err error
+		diversity, err = strconv.ParseFloat(val, 64)
+		if err != nil || !isFinite(diversity) || diversity < 0 || diversity > 1 {
+			http.Error(w, "diversity must be  >= 0 && <= 1", 400)
+			return
+		}
+	}
+	novelty := 0.0
+	if val := r.FormValue("novelty"); val != "" {
+		var err error
+		novelty, err = strconv.ParseFloat(val, 64)
+		if err != nil || !isFinite(novelty) || novelty < 0 {
+			http.Error(w, "novelty must be  >= 0", 400)
+			return
+		}
+	}
+	rec, err := parseRecency(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	filter, err := parseStoryFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	gems := false
+	if val := r.FormValue("gems"); val != "" {
+		gems, err = strconv.ParseBool(val)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid gems: %q", val), 400)
+			return
+		}
+	}
+	// Hidden gems mode defaults to a strong popularity penalty and leaves out
+	// widely favorited stories.
+	if gems {
+		if novelty == 0 {
+			novelty = 1
+		}
+		if filter.MaxFavorites == 0 {
+			filter.MaxFavorites = hiddenGemsMaxFavorites
+		}
+	}
+	crossSite := false
+	if val := r.FormValue("cross_site"); val != "" {
+		crossSite, err = strconv.ParseBool(val)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid cross_site: %q", val), 400)
+			return
+		}
+	}
+	var after *cursor
+	if val := r.FormValue("cursor"); val != "" {
+		c, err := decodeCursor(val)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		if c.Key != "" {
+			after = &c
+		} else {
+			offset = c.Offset
+		}
+	}
+	opts := recOptions{
+		Limit:     limit,
+		Offset:    offset,
+		Filter:    filter,
+		Diversity: diversity,
+		Novelty:   novelty,
+		Recency:   rec,
+		CrossSite: crossSite,
+		After:     after,
+	}
+	// Callers that don't pick an algorithm take part in the experiment.
+	var exp *assignment
+	if algo == "" {
+		exp, err = s.assignVariant(w, r, &algo, &opts)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+	}
+	resp, err := s.recommendations(id, neg, algo, opts)
 	if err != nil {
 		http.Error(w, err.Error(), 500)
 		return
 	}
+	if exp != nil {
+		resp.Experiment = exp
+		if err := s.recordEvent(*exp, eventImpression, strconv.Itoa(len(resp.Stories))); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+	}
+	writeJSONP(w, r, resp)
+}
+
+// writeJSONP writes v as JSON, wrapped in the callback parameter if set.
+func writeJSONP(w http.ResponseWriter, r *http.Request, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 
-	jsonBytes, err := json.Marshal(resp)
+	jsonBytes, err := json.Marshal(v)
 	if err != nil {
 		http.Error(w, err.Error(), 500)
 		return
@@ -171,29 +324,50 @@ var (
 	dbpath = flag.String("dbpath", "./recommender.badger", "database directory")
 )
 
-func loadDB(path string) (*badger.DB, error) {
-	opts := badger.DefaultOptions
-	opts.Dir = path
-	opts.ValueDir = path
-	return badger.Open(opts)
-}
-
 type server struct {
-	db *badger.DB
+	db Store
+
+	mu             sync.Mutex
+	userCountCache int
+	userCountTime  time.Time
+
+	alsMu sync.Mutex
+	als   *alsModel
+
+	experiments []*experiment
+
+	rankings *rankingCache
 }
 
 func newServer() (*server, error) {
-	s := &server{}
-
-	db, err := loadDB(*dbpath)
+	db, err := openStore(*dbpath)
 	if err != nil {
 		return nil, err
 	}
-	s.db = db
+	s := newStoreServer(db)
+	if err := s.checkSchema(); err != nil {
+		return nil, err
+	}
+
+	if *experimentsPath != "" {
+		s.experiments, err = loadExperiments(*experimentsPath)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return s, nil
 }
 
+// newStoreServer returns a server using the store, e.g. a memoryStore filled
+// with test data.
+func newStoreServer(db Store) *server {
+	return &server{
+		db:       db,
+		rankings: newRankingCache(),
+	}
+}
+
 func (s *server) startScraping() {
 	log.Print("starting scraping...")
 	for _, scraper := range scrapers {
@@ -221,18 +395,39 @@ func run() error {
 	args := flag.Args()
 	log.Println(args)
 	if len(args) > 0 {
-		return s.cmdGet(args[0], args[1])
+		switch args[0] {
+		case "train-als":
+			return s.cmdTrainALS()
+		case "link-authors":
+			return s.cmdLinkAuthors(args[1:])
+		case "evaluate":
+			return s.cmdEvaluate()
+		case "experiment-report":
+			return s.cmdExperimentReport()
+		case "migrate":
+			return s.cmdMigrate()
+		default:
+			return s.cmdGet(args[0], args[1])
+		}
 	}
 
 	if *scrape {
 		s.startScraping()
 	}
+	if *buildIndex {
+		s.startIndexing()
+	}
 
 	fs := http.FileServer(http.Dir("."))
 	http.Handle("/static/", fs)
 
 	http.HandleFunc("/", handleIndex)
 	http.HandleFunc("/api/v1/recommendation", s.handleRecommendation)
+	http.HandleFunc("/api/v1/authors/similar", s.handleSimilarAuthors)
+	http.HandleFunc("/api/v1/authors/stories", s.handleStoryList(authorStoriesPrefix))
+	http.HandleFunc("/api/v1/categories/stories", s.handleStoryList(categoryStoriesPrefix))
+	http.HandleFunc("/api/v1/click", s.handleClick)
+	http.HandleFunc("/api/v1/experiments", s.handleExperiments)
 
 	log.Printf("Serving on :%s...", *port)
 
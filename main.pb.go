@@ -11,6 +11,7 @@ import strconv "strconv"
 
 import strings "strings"
 import reflect "reflect"
+import github_com_gogo_protobuf_sortkeys "github.com/gogo/protobuf/sortkeys"
 
 import encoding_binary "encoding/binary"
 
@@ -47,26 +48,28 @@ var Site_value = map[string]int32{
 }
 
 func (Site) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_main_902b1cd6a44eeb55, []int{0}
+	return fileDescriptor_main_a6cdd95900528566, []int{0}
 }
 
 type User struct {
-	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Exists               bool     `protobuf:"varint,2,opt,name=exists,proto3" json:"exists,omitempty"`
-	Name                 string   `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
-	Stories              []string `protobuf:"bytes,4,rep,name=stories" json:"stories,omitempty"`
-	FavStories           []string `protobuf:"bytes,5,rep,name=fav_stories,json=favStories" json:"fav_stories,omitempty"`
-	FavAuthors           []string `protobuf:"bytes,6,rep,name=fav_authors,json=favAuthors" json:"fav_authors,omitempty"`
-	FavedBy              []string `protobuf:"bytes,7,rep,name=faved_by,json=favedBy" json:"faved_by,omitempty"`
-	Site                 Site     `protobuf:"varint,8,opt,name=site,proto3,enum=Site" json:"site,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Id         string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Exists     bool     `protobuf:"varint,2,opt,name=exists,proto3" json:"exists,omitempty"`
+	Name       string   `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Stories    []string `protobuf:"bytes,4,rep,name=stories" json:"stories,omitempty"`
+	FavStories []string `protobuf:"bytes,5,rep,name=fav_stories,json=favStories" json:"fav_stories,omitempty"`
+	FavAuthors []string `protobuf:"bytes,6,rep,name=fav_authors,json=favAuthors" json:"fav_authors,omitempty"`
+	FavedBy    []string `protobuf:"bytes,7,rep,name=faved_by,json=favedBy" json:"faved_by,omitempty"`
+	Site       Site     `protobuf:"varint,8,opt,name=site,proto3,enum=Site" json:"site,omitempty"`
+	// fav_seen is when each favorite story was first seen, in unix seconds.
+	FavSeen              map[string]int64 `protobuf:"bytes,9,rep,name=fav_seen,json=favSeen" json:"fav_seen,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
 func (m *User) Reset()      { *m = User{} }
 func (*User) ProtoMessage() {}
 func (*User) Descriptor() ([]byte, []int) {
-	return fileDescriptor_main_902b1cd6a44eeb55, []int{0}
+	return fileDescriptor_main_a6cdd95900528566, []int{0}
 }
 func (m *User) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -151,25 +154,36 @@ func (m *User) GetSite() Site {
 	return FFNET
 }
 
+func (m *User) GetFavSeen() map[string]int64 {
+	if m != nil {
+		return m.FavSeen
+	}
+	return nil
+}
+
 type Story struct {
-	Id                   int32    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title                string   `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Category             string   `protobuf:"bytes,3,opt,name=category,proto3" json:"category,omitempty"`
-	Image                string   `protobuf:"bytes,4,opt,name=image,proto3" json:"image,omitempty"`
-	Desc                 string   `protobuf:"bytes,5,opt,name=desc,proto3" json:"desc,omitempty"`
-	Url                  string   `protobuf:"bytes,6,opt,name=url,proto3" json:"url,omitempty"`
-	Dl                   string   `protobuf:"bytes,7,opt,name=dl,proto3" json:"dl,omitempty"`
-	WordCount            int32    `protobuf:"varint,8,opt,name=word_count,json=wordCount,proto3" json:"word_count,omitempty"`
-	DateSubmit           int32    `protobuf:"varint,9,opt,name=date_submit,json=dateSubmit,proto3" json:"date_submit,omitempty"`
-	DateUpdate           int32    `protobuf:"varint,10,opt,name=date_update,json=dateUpdate,proto3" json:"date_update,omitempty"`
-	Reviews              int32    `protobuf:"varint,11,opt,name=reviews,proto3" json:"reviews,omitempty"`
-	Chapters             int32    `protobuf:"varint,12,opt,name=chapters,proto3" json:"chapters,omitempty"`
-	Favorites            int32    `protobuf:"varint,17,opt,name=favorites,proto3" json:"favorites,omitempty"`
-	Complete             bool     `protobuf:"varint,13,opt,name=complete,proto3" json:"complete,omitempty"`
-	FavedBy              []string `protobuf:"bytes,14,rep,name=faved_by,json=favedBy" json:"faved_by,omitempty"`
-	Site                 Site     `protobuf:"varint,15,opt,name=site,proto3,enum=Site" json:"site,omitempty"`
-	Exists               bool     `protobuf:"varint,16,opt,name=exists,proto3" json:"exists,omitempty"`
-	Score                float32  `protobuf:"fixed32,18,opt,name=score,proto3" json:"score,omitempty"`
+	Id         int32    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title      string   `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Category   string   `protobuf:"bytes,3,opt,name=category,proto3" json:"category,omitempty"`
+	Image      string   `protobuf:"bytes,4,opt,name=image,proto3" json:"image,omitempty"`
+	Desc       string   `protobuf:"bytes,5,opt,name=desc,proto3" json:"desc,omitempty"`
+	Url        string   `protobuf:"bytes,6,opt,name=url,proto3" json:"url,omitempty"`
+	Dl         string   `protobuf:"bytes,7,opt,name=dl,proto3" json:"dl,omitempty"`
+	WordCount  int32    `protobuf:"varint,8,opt,name=word_count,json=wordCount,proto3" json:"word_count,omitempty"`
+	DateSubmit int32    `protobuf:"varint,9,opt,name=date_submit,json=dateSubmit,proto3" json:"date_submit,omitempty"`
+	DateUpdate int32    `protobuf:"varint,10,opt,name=date_update,json=dateUpdate,proto3" json:"date_update,omitempty"`
+	Reviews    int32    `protobuf:"varint,11,opt,name=reviews,proto3" json:"reviews,omitempty"`
+	Chapters   int32    `protobuf:"varint,12,opt,name=chapters,proto3" json:"chapters,omitempty"`
+	Favorites  int32    `protobuf:"varint,17,opt,name=favorites,proto3" json:"favorites,omitempty"`
+	Complete   bool     `protobuf:"varint,13,opt,name=complete,proto3" json:"complete,omitempty"`
+	FavedBy    []string `protobuf:"bytes,14,rep,name=faved_by,json=favedBy" json:"faved_by,omitempty"`
+	Site       Site     `protobuf:"varint,15,opt,name=site,proto3,enum=Site" json:"site,omitempty"`
+	Exists     bool     `protobuf:"varint,16,opt,name=exists,proto3" json:"exists,omitempty"`
+	Score      float32  `protobuf:"fixed32,18,opt,name=score,proto3" json:"score,omitempty"`
+	// author_id is the site's user id of the author and author_name their pen
+	// name.
+	AuthorId             string   `protobuf:"bytes,19,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	AuthorName           string   `protobuf:"bytes,20,opt,name=author_name,json=authorName,proto3" json:"author_name,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
@@ -177,7 +191,7 @@ type Story struct {
 func (m *Story) Reset()      { *m = Story{} }
 func (*Story) ProtoMessage() {}
 func (*Story) Descriptor() ([]byte, []int) {
-	return fileDescriptor_main_902b1cd6a44eeb55, []int{1}
+	return fileDescriptor_main_a6cdd95900528566, []int{1}
 }
 func (m *Story) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -332,9 +346,286 @@ func (m *Story) GetScore() float32 {
 	return 0
 }
 
+func (m *Story) GetAuthorId() string {
+	if m != nil {
+		return m.AuthorId
+	}
+	return ""
+}
+
+func (m *Story) GetAuthorName() string {
+	if m != nil {
+		return m.AuthorName
+	}
+	return ""
+}
+
+// Neighbors are the stories most often favorited together with a story.
+type Neighbors struct {
+	Neighbors []*Neighbor `protobuf:"bytes,1,rep,name=neighbors" json:"neighbors,omitempty"`
+	// fans is the number of fans the counts were computed from.
+	Fans                 int32    `protobuf:"varint,2,opt,name=fans,proto3" json:"fans,omitempty"`
+	Updated              int64    `protobuf:"varint,3,opt,name=updated,proto3" json:"updated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Neighbors) Reset()      { *m = Neighbors{} }
+func (*Neighbors) ProtoMessage() {}
+func (*Neighbors) Descriptor() ([]byte, []int) {
+	return fileDescriptor_main_a6cdd95900528566, []int{2}
+}
+func (m *Neighbors) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Neighbors) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Neighbors.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (dst *Neighbors) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Neighbors.Merge(dst, src)
+}
+func (m *Neighbors) XXX_Size() int {
+	return m.Size()
+}
+func (m *Neighbors) XXX_DiscardUnknown() {
+	xxx_messageInfo_Neighbors.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Neighbors proto.InternalMessageInfo
+
+func (m *Neighbors) GetNeighbors() []*Neighbor {
+	if m != nil {
+		return m.Neighbors
+	}
+	return nil
+}
+
+func (m *Neighbors) GetFans() int32 {
+	if m != nil {
+		return m.Fans
+	}
+	return 0
+}
+
+func (m *Neighbors) GetUpdated() int64 {
+	if m != nil {
+		return m.Updated
+	}
+	return 0
+}
+
+type Neighbor struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Count                float32  `protobuf:"fixed32,2,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Neighbor) Reset()      { *m = Neighbor{} }
+func (*Neighbor) ProtoMessage() {}
+func (*Neighbor) Descriptor() ([]byte, []int) {
+	return fileDescriptor_main_a6cdd95900528566, []int{3}
+}
+func (m *Neighbor) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Neighbor) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Neighbor.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (dst *Neighbor) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Neighbor.Merge(dst, src)
+}
+func (m *Neighbor) XXX_Size() int {
+	return m.Size()
+}
+func (m *Neighbor) XXX_DiscardUnknown() {
+	xxx_messageInfo_Neighbor.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Neighbor proto.InternalMessageInfo
+
+func (m *Neighbor) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Neighbor) GetCount() float32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+// ALSModel is the metadata of a matrix factorization model trained with
+// implicit feedback alternating least squares.
+type ALSModel struct {
+	Factors int32   `protobuf:"varint,1,opt,name=factors,proto3" json:"factors,omitempty"`
+	Lambda  float32 `protobuf:"fixed32,2,opt,name=lambda,proto3" json:"lambda,omitempty"`
+	Alpha   float32 `protobuf:"fixed32,3,opt,name=alpha,proto3" json:"alpha,omitempty"`
+	// yty is the row major gramian of the story factors.
+	Yty                  []float64 `protobuf:"fixed64,4,rep,packed,name=yty" json:"yty,omitempty"`
+	Trained              int64     `protobuf:"varint,5,opt,name=trained,proto3" json:"trained,omitempty"`
+	Stories              int32     `protobuf:"varint,6,opt,name=stories,proto3" json:"stories,omitempty"`
+	Users                int32     `protobuf:"varint,7,opt,name=users,proto3" json:"users,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ALSModel) Reset()      { *m = ALSModel{} }
+func (*ALSModel) ProtoMessage() {}
+func (*ALSModel) Descriptor() ([]byte, []int) {
+	return fileDescriptor_main_a6cdd95900528566, []int{4}
+}
+func (m *ALSModel) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ALSModel) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ALSModel.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (dst *ALSModel) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ALSModel.Merge(dst, src)
+}
+func (m *ALSModel) XXX_Size() int {
+	return m.Size()
+}
+func (m *ALSModel) XXX_DiscardUnknown() {
+	xxx_messageInfo_ALSModel.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ALSModel proto.InternalMessageInfo
+
+func (m *ALSModel) GetFactors() int32 {
+	if m != nil {
+		return m.Factors
+	}
+	return 0
+}
+
+func (m *ALSModel) GetLambda() float32 {
+	if m != nil {
+		return m.Lambda
+	}
+	return 0
+}
+
+func (m *ALSModel) GetAlpha() float32 {
+	if m != nil {
+		return m.Alpha
+	}
+	return 0
+}
+
+func (m *ALSModel) GetYty() []float64 {
+	if m != nil {
+		return m.Yty
+	}
+	return nil
+}
+
+func (m *ALSModel) GetTrained() int64 {
+	if m != nil {
+		return m.Trained
+	}
+	return 0
+}
+
+func (m *ALSModel) GetStories() int32 {
+	if m != nil {
+		return m.Stories
+	}
+	return 0
+}
+
+func (m *ALSModel) GetUsers() int32 {
+	if m != nil {
+		return m.Users
+	}
+	return 0
+}
+
+// Factors are the latent factors of a story or user.
+type Factors struct {
+	Values               []float32 `protobuf:"fixed32,1,rep,packed,name=values" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Factors) Reset()      { *m = Factors{} }
+func (*Factors) ProtoMessage() {}
+func (*Factors) Descriptor() ([]byte, []int) {
+	return fileDescriptor_main_a6cdd95900528566, []int{5}
+}
+func (m *Factors) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Factors) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Factors.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (dst *Factors) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Factors.Merge(dst, src)
+}
+func (m *Factors) XXX_Size() int {
+	return m.Size()
+}
+func (m *Factors) XXX_DiscardUnknown() {
+	xxx_messageInfo_Factors.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Factors proto.InternalMessageInfo
+
+func (m *Factors) GetValues() []float32 {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*User)(nil), "User")
+	proto.RegisterMapType((map[string]int64)(nil), "User.FavSeenEntry")
 	proto.RegisterType((*Story)(nil), "Story")
+	proto.RegisterType((*Neighbors)(nil), "Neighbors")
+	proto.RegisterType((*Neighbor)(nil), "Neighbor")
+	proto.RegisterType((*ALSModel)(nil), "ALSModel")
+	proto.RegisterType((*Factors)(nil), "Factors")
 	proto.RegisterEnum("Site", Site_name, Site_value)
 }
 func (x Site) String() string {
@@ -407,6 +698,14 @@ func (this *User) Equal(that interface{}) bool {
 	if this.Site != that1.Site {
 		return false
 	}
+	if len(this.FavSeen) != len(that1.FavSeen) {
+		return false
+	}
+	for i := range this.FavSeen {
+		if this.FavSeen[i] != that1.FavSeen[i] {
+			return false
+		}
+	}
 	return true
 }
 func (this *Story) Equal(that interface{}) bool {
@@ -487,68 +786,278 @@ func (this *Story) Equal(that interface{}) bool {
 	if this.Score != that1.Score {
 		return false
 	}
+	if this.AuthorId != that1.AuthorId {
+		return false
+	}
+	if this.AuthorName != that1.AuthorName {
+		return false
+	}
 	return true
 }
-func (this *User) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *Neighbors) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
 	}
-	s := make([]string, 0, 12)
-	s = append(s, "&main.User{")
-	s = append(s, "Id: "+fmt.Sprintf("%#v", this.Id)+",\n")
-	s = append(s, "Exists: "+fmt.Sprintf("%#v", this.Exists)+",\n")
-	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
-	s = append(s, "Stories: "+fmt.Sprintf("%#v", this.Stories)+",\n")
-	s = append(s, "FavStories: "+fmt.Sprintf("%#v", this.FavStories)+",\n")
-	s = append(s, "FavAuthors: "+fmt.Sprintf("%#v", this.FavAuthors)+",\n")
-	s = append(s, "FavedBy: "+fmt.Sprintf("%#v", this.FavedBy)+",\n")
-	s = append(s, "Site: "+fmt.Sprintf("%#v", this.Site)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *Story) GoString() string {
-	if this == nil {
-		return "nil"
+
+	that1, ok := that.(*Neighbors)
+	if !ok {
+		that2, ok := that.(Neighbors)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s := make([]string, 0, 22)
-	s = append(s, "&main.Story{")
-	s = append(s, "Id: "+fmt.Sprintf("%#v", this.Id)+",\n")
-	s = append(s, "Title: "+fmt.Sprintf("%#v", this.Title)+",\n")
-	s = append(s, "Category: "+fmt.Sprintf("%#v", this.Category)+",\n")
-	s = append(s, "Image: "+fmt.Sprintf("%#v", this.Image)+",\n")
-	s = append(s, "Desc: "+fmt.Sprintf("%#v", this.Desc)+",\n")
-	s = append(s, "Url: "+fmt.Sprintf("%#v", this.Url)+",\n")
-	s = append(s, "Dl: "+fmt.Sprintf("%#v", this.Dl)+",\n")
-	s = append(s, "WordCount: "+fmt.Sprintf("%#v", this.WordCount)+",\n")
-	s = append(s, "DateSubmit: "+fmt.Sprintf("%#v", this.DateSubmit)+",\n")
-	s = append(s, "DateUpdate: "+fmt.Sprintf("%#v", this.DateUpdate)+",\n")
-	s = append(s, "Reviews: "+fmt.Sprintf("%#v", this.Reviews)+",\n")
-	s = append(s, "Chapters: "+fmt.Sprintf("%#v", this.Chapters)+",\n")
-	s = append(s, "Favorites: "+fmt.Sprintf("%#v", this.Favorites)+",\n")
-	s = append(s, "Complete: "+fmt.Sprintf("%#v", this.Complete)+",\n")
-	s = append(s, "FavedBy: "+fmt.Sprintf("%#v", this.FavedBy)+",\n")
-	s = append(s, "Site: "+fmt.Sprintf("%#v", this.Site)+",\n")
-	s = append(s, "Exists: "+fmt.Sprintf("%#v", this.Exists)+",\n")
-	s = append(s, "Score: "+fmt.Sprintf("%#v", this.Score)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func valueToGoStringMain(v interface{}, typ string) string {
-	rv := reflect.ValueOf(v)
-	if rv.IsNil() {
-		return "nil"
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
 	}
-	pv := reflect.Indirect(rv).Interface()
-	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
-}
-func (m *User) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if len(this.Neighbors) != len(that1.Neighbors) {
+		return false
 	}
-	return dAtA[:n], nil
+	for i := range this.Neighbors {
+		if !this.Neighbors[i].Equal(that1.Neighbors[i]) {
+			return false
+		}
+	}
+	if this.Fans != that1.Fans {
+		return false
+	}
+	if this.Updated != that1.Updated {
+		return false
+	}
+	return true
+}
+func (this *Neighbor) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*Neighbor)
+	if !ok {
+		that2, ok := that.(Neighbor)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Key != that1.Key {
+		return false
+	}
+	if this.Count != that1.Count {
+		return false
+	}
+	return true
+}
+func (this *ALSModel) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ALSModel)
+	if !ok {
+		that2, ok := that.(ALSModel)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Factors != that1.Factors {
+		return false
+	}
+	if this.Lambda != that1.Lambda {
+		return false
+	}
+	if this.Alpha != that1.Alpha {
+		return false
+	}
+	if len(this.Yty) != len(that1.Yty) {
+		return false
+	}
+	for i := range this.Yty {
+		if this.Yty[i] != that1.Yty[i] {
+			return false
+		}
+	}
+	if this.Trained != that1.Trained {
+		return false
+	}
+	if this.Stories != that1.Stories {
+		return false
+	}
+	if this.Users != that1.Users {
+		return false
+	}
+	return true
+}
+func (this *Factors) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*Factors)
+	if !ok {
+		that2, ok := that.(Factors)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Values) != len(that1.Values) {
+		return false
+	}
+	for i := range this.Values {
+		if this.Values[i] != that1.Values[i] {
+			return false
+		}
+	}
+	return true
+}
+func (this *User) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 13)
+	s = append(s, "&main.User{")
+	s = append(s, "Id: "+fmt.Sprintf("%#v", this.Id)+",\n")
+	s = append(s, "Exists: "+fmt.Sprintf("%#v", this.Exists)+",\n")
+	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
+	s = append(s, "Stories: "+fmt.Sprintf("%#v", this.Stories)+",\n")
+	s = append(s, "FavStories: "+fmt.Sprintf("%#v", this.FavStories)+",\n")
+	s = append(s, "FavAuthors: "+fmt.Sprintf("%#v", this.FavAuthors)+",\n")
+	s = append(s, "FavedBy: "+fmt.Sprintf("%#v", this.FavedBy)+",\n")
+	s = append(s, "Site: "+fmt.Sprintf("%#v", this.Site)+",\n")
+	keysForFavSeen := make([]string, 0, len(this.FavSeen))
+	for k, _ := range this.FavSeen {
+		keysForFavSeen = append(keysForFavSeen, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForFavSeen)
+	mapStringForFavSeen := "map[string]int64{"
+	for _, k := range keysForFavSeen {
+		mapStringForFavSeen += fmt.Sprintf("%#v: %#v,", k, this.FavSeen[k])
+	}
+	mapStringForFavSeen += "}"
+	if this.FavSeen != nil {
+		s = append(s, "FavSeen: "+mapStringForFavSeen+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Story) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 24)
+	s = append(s, "&main.Story{")
+	s = append(s, "Id: "+fmt.Sprintf("%#v", this.Id)+",\n")
+	s = append(s, "Title: "+fmt.Sprintf("%#v", this.Title)+",\n")
+	s = append(s, "Category: "+fmt.Sprintf("%#v", this.Category)+",\n")
+	s = append(s, "Image: "+fmt.Sprintf("%#v", this.Image)+",\n")
+	s = append(s, "Desc: "+fmt.Sprintf("%#v", this.Desc)+",\n")
+	s = append(s, "Url: "+fmt.Sprintf("%#v", this.Url)+",\n")
+	s = append(s, "Dl: "+fmt.Sprintf("%#v", this.Dl)+",\n")
+	s = append(s, "WordCount: "+fmt.Sprintf("%#v", this.WordCount)+",\n")
+	s = append(s, "DateSubmit: "+fmt.Sprintf("%#v", this.DateSubmit)+",\n")
+	s = append(s, "DateUpdate: "+fmt.Sprintf("%#v", this.DateUpdate)+",\n")
+	s = append(s, "Reviews: "+fmt.Sprintf("%#v", this.Reviews)+",\n")
+	s = append(s, "Chapters: "+fmt.Sprintf("%#v", this.Chapters)+",\n")
+	s = append(s, "Favorites: "+fmt.Sprintf("%#v", this.Favorites)+",\n")
+	s = append(s, "Complete: "+fmt.Sprintf("%#v", this.Complete)+",\n")
+	s = append(s, "FavedBy: "+fmt.Sprintf("%#v", this.FavedBy)+",\n")
+	s = append(s, "Site: "+fmt.Sprintf("%#v", this.Site)+",\n")
+	s = append(s, "Exists: "+fmt.Sprintf("%#v", this.Exists)+",\n")
+	s = append(s, "Score: "+fmt.Sprintf("%#v", this.Score)+",\n")
+	s = append(s, "AuthorId: "+fmt.Sprintf("%#v", this.AuthorId)+",\n")
+	s = append(s, "AuthorName: "+fmt.Sprintf("%#v", this.AuthorName)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Neighbors) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&main.Neighbors{")
+	if this.Neighbors != nil {
+		s = append(s, "Neighbors: "+fmt.Sprintf("%#v", this.Neighbors)+",\n")
+	}
+	s = append(s, "Fans: "+fmt.Sprintf("%#v", this.Fans)+",\n")
+	s = append(s, "Updated: "+fmt.Sprintf("%#v", this.Updated)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Neighbor) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&main.Neighbor{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	s = append(s, "Count: "+fmt.Sprintf("%#v", this.Count)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ALSModel) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 11)
+	s = append(s, "&main.ALSModel{")
+	s = append(s, "Factors: "+fmt.Sprintf("%#v", this.Factors)+",\n")
+	s = append(s, "Lambda: "+fmt.Sprintf("%#v", this.Lambda)+",\n")
+	s = append(s, "Alpha: "+fmt.Sprintf("%#v", this.Alpha)+",\n")
+	s = append(s, "Yty: "+fmt.Sprintf("%#v", this.Yty)+",\n")
+	s = append(s, "Trained: "+fmt.Sprintf("%#v", this.Trained)+",\n")
+	s = append(s, "Stories: "+fmt.Sprintf("%#v", this.Stories)+",\n")
+	s = append(s, "Users: "+fmt.Sprintf("%#v", this.Users)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Factors) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&main.Factors{")
+	s = append(s, "Values: "+fmt.Sprintf("%#v", this.Values)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func valueToGoStringMain(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+}
+func (m *User) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
 }
 
 func (m *User) MarshalTo(dAtA []byte) (int, error) {
@@ -643,6 +1152,22 @@ func (m *User) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintMain(dAtA, i, uint64(m.Site))
 	}
+	if len(m.FavSeen) > 0 {
+		for k, _ := range m.FavSeen {
+			dAtA[i] = 0x4a
+			i++
+			v := m.FavSeen[k]
+			mapSize := 1 + len(k) + sovMain(uint64(len(k))) + 1 + sovMain(uint64(v))
+			i = encodeVarintMain(dAtA, i, uint64(mapSize))
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintMain(dAtA, i, uint64(len(k)))
+			i += copy(dAtA[i:], k)
+			dAtA[i] = 0x10
+			i++
+			i = encodeVarintMain(dAtA, i, uint64(v))
+		}
+	}
 	return i, nil
 }
 
@@ -784,70 +1309,252 @@ func (m *Story) MarshalTo(dAtA []byte) (int, error) {
 		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Score))))
 		i += 4
 	}
+	if len(m.AuthorId) > 0 {
+		dAtA[i] = 0x9a
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintMain(dAtA, i, uint64(len(m.AuthorId)))
+		i += copy(dAtA[i:], m.AuthorId)
+	}
+	if len(m.AuthorName) > 0 {
+		dAtA[i] = 0xa2
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintMain(dAtA, i, uint64(len(m.AuthorName)))
+		i += copy(dAtA[i:], m.AuthorName)
+	}
 	return i, nil
 }
 
-func encodeVarintMain(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *Neighbors) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
+	return dAtA[:n], nil
 }
-func (m *User) Size() (n int) {
+
+func (m *Neighbors) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	l = len(m.Id)
-	if l > 0 {
-		n += 1 + l + sovMain(uint64(l))
-	}
-	if m.Exists {
-		n += 2
-	}
-	l = len(m.Name)
-	if l > 0 {
-		n += 1 + l + sovMain(uint64(l))
-	}
-	if len(m.Stories) > 0 {
-		for _, s := range m.Stories {
-			l = len(s)
-			n += 1 + l + sovMain(uint64(l))
-		}
-	}
-	if len(m.FavStories) > 0 {
-		for _, s := range m.FavStories {
-			l = len(s)
-			n += 1 + l + sovMain(uint64(l))
+	if len(m.Neighbors) > 0 {
+		for _, msg := range m.Neighbors {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintMain(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
 		}
 	}
-	if len(m.FavAuthors) > 0 {
-		for _, s := range m.FavAuthors {
-			l = len(s)
-			n += 1 + l + sovMain(uint64(l))
-		}
+	if m.Fans != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintMain(dAtA, i, uint64(m.Fans))
 	}
-	if len(m.FavedBy) > 0 {
-		for _, s := range m.FavedBy {
-			l = len(s)
-			n += 1 + l + sovMain(uint64(l))
-		}
+	if m.Updated != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintMain(dAtA, i, uint64(m.Updated))
 	}
-	if m.Site != 0 {
-		n += 1 + sovMain(uint64(m.Site))
+	return i, nil
+}
+
+func (m *Neighbor) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *Story) Size() (n int) {
+func (m *Neighbor) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.Id != 0 {
-		n += 1 + sovMain(uint64(m.Id))
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMain(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
 	}
-	l = len(m.Title)
-	if l > 0 {
+	if m.Count != 0 {
+		dAtA[i] = 0x15
+		i++
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Count))))
+		i += 4
+	}
+	return i, nil
+}
+
+func (m *ALSModel) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ALSModel) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Factors != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintMain(dAtA, i, uint64(m.Factors))
+	}
+	if m.Lambda != 0 {
+		dAtA[i] = 0x15
+		i++
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Lambda))))
+		i += 4
+	}
+	if m.Alpha != 0 {
+		dAtA[i] = 0x1d
+		i++
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Alpha))))
+		i += 4
+	}
+	if len(m.Yty) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMain(dAtA, i, uint64(len(m.Yty)*8))
+		for _, num := range m.Yty {
+			f1 := math.Float64bits(float64(num))
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f1))
+			i += 8
+		}
+	}
+	if m.Trained != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintMain(dAtA, i, uint64(m.Trained))
+	}
+	if m.Stories != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintMain(dAtA, i, uint64(m.Stories))
+	}
+	if m.Users != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintMain(dAtA, i, uint64(m.Users))
+	}
+	return i, nil
+}
+
+func (m *Factors) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Factors) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMain(dAtA, i, uint64(len(m.Values)*4))
+		for _, num := range m.Values {
+			f2 := math.Float32bits(float32(num))
+			encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(f2))
+			i += 4
+		}
+	}
+	return i, nil
+}
+
+func encodeVarintMain(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *User) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Id)
+	if l > 0 {
+		n += 1 + l + sovMain(uint64(l))
+	}
+	if m.Exists {
+		n += 2
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovMain(uint64(l))
+	}
+	if len(m.Stories) > 0 {
+		for _, s := range m.Stories {
+			l = len(s)
+			n += 1 + l + sovMain(uint64(l))
+		}
+	}
+	if len(m.FavStories) > 0 {
+		for _, s := range m.FavStories {
+			l = len(s)
+			n += 1 + l + sovMain(uint64(l))
+		}
+	}
+	if len(m.FavAuthors) > 0 {
+		for _, s := range m.FavAuthors {
+			l = len(s)
+			n += 1 + l + sovMain(uint64(l))
+		}
+	}
+	if len(m.FavedBy) > 0 {
+		for _, s := range m.FavedBy {
+			l = len(s)
+			n += 1 + l + sovMain(uint64(l))
+		}
+	}
+	if m.Site != 0 {
+		n += 1 + sovMain(uint64(m.Site))
+	}
+	if len(m.FavSeen) > 0 {
+		for k, v := range m.FavSeen {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovMain(uint64(len(k))) + 1 + sovMain(uint64(v))
+			n += mapEntrySize + 1 + sovMain(uint64(mapEntrySize))
+		}
+	}
+	return n
+}
+
+func (m *Story) Size() (n int) {
+	var l int
+	_ = l
+	if m.Id != 0 {
+		n += 1 + sovMain(uint64(m.Id))
+	}
+	l = len(m.Title)
+	if l > 0 {
 		n += 1 + l + sovMain(uint64(l))
 	}
 	l = len(m.Category)
@@ -906,6 +1613,81 @@ func (m *Story) Size() (n int) {
 	if m.Score != 0 {
 		n += 6
 	}
+	l = len(m.AuthorId)
+	if l > 0 {
+		n += 2 + l + sovMain(uint64(l))
+	}
+	l = len(m.AuthorName)
+	if l > 0 {
+		n += 2 + l + sovMain(uint64(l))
+	}
+	return n
+}
+
+func (m *Neighbors) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Neighbors) > 0 {
+		for _, e := range m.Neighbors {
+			l = e.Size()
+			n += 1 + l + sovMain(uint64(l))
+		}
+	}
+	if m.Fans != 0 {
+		n += 1 + sovMain(uint64(m.Fans))
+	}
+	if m.Updated != 0 {
+		n += 1 + sovMain(uint64(m.Updated))
+	}
+	return n
+}
+
+func (m *Neighbor) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovMain(uint64(l))
+	}
+	if m.Count != 0 {
+		n += 5
+	}
+	return n
+}
+
+func (m *ALSModel) Size() (n int) {
+	var l int
+	_ = l
+	if m.Factors != 0 {
+		n += 1 + sovMain(uint64(m.Factors))
+	}
+	if m.Lambda != 0 {
+		n += 5
+	}
+	if m.Alpha != 0 {
+		n += 5
+	}
+	if len(m.Yty) > 0 {
+		n += 1 + sovMain(uint64(len(m.Yty)*8)) + len(m.Yty)*8
+	}
+	if m.Trained != 0 {
+		n += 1 + sovMain(uint64(m.Trained))
+	}
+	if m.Stories != 0 {
+		n += 1 + sovMain(uint64(m.Stories))
+	}
+	if m.Users != 0 {
+		n += 1 + sovMain(uint64(m.Users))
+	}
+	return n
+}
+
+func (m *Factors) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		n += 1 + sovMain(uint64(len(m.Values)*4)) + len(m.Values)*4
+	}
 	return n
 }
 
@@ -926,6 +1708,16 @@ func (this *User) String() string {
 	if this == nil {
 		return "nil"
 	}
+	keysForFavSeen := make([]string, 0, len(this.FavSeen))
+	for k, _ := range this.FavSeen {
+		keysForFavSeen = append(keysForFavSeen, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForFavSeen)
+	mapStringForFavSeen := "map[string]int64{"
+	for _, k := range keysForFavSeen {
+		mapStringForFavSeen += fmt.Sprintf("%v: %v,", k, this.FavSeen[k])
+	}
+	mapStringForFavSeen += "}"
 	s := strings.Join([]string{`&User{`,
 		`Id:` + fmt.Sprintf("%v", this.Id) + `,`,
 		`Exists:` + fmt.Sprintf("%v", this.Exists) + `,`,
@@ -935,6 +1727,7 @@ func (this *User) String() string {
 		`FavAuthors:` + fmt.Sprintf("%v", this.FavAuthors) + `,`,
 		`FavedBy:` + fmt.Sprintf("%v", this.FavedBy) + `,`,
 		`Site:` + fmt.Sprintf("%v", this.Site) + `,`,
+		`FavSeen:` + mapStringForFavSeen + `,`,
 		`}`,
 	}, "")
 	return s
@@ -962,6 +1755,57 @@ func (this *Story) String() string {
 		`Exists:` + fmt.Sprintf("%v", this.Exists) + `,`,
 		`Favorites:` + fmt.Sprintf("%v", this.Favorites) + `,`,
 		`Score:` + fmt.Sprintf("%v", this.Score) + `,`,
+		`AuthorId:` + fmt.Sprintf("%v", this.AuthorId) + `,`,
+		`AuthorName:` + fmt.Sprintf("%v", this.AuthorName) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Neighbors) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Neighbors{`,
+		`Neighbors:` + strings.Replace(fmt.Sprintf("%v", this.Neighbors), "Neighbor", "Neighbor", 1) + `,`,
+		`Fans:` + fmt.Sprintf("%v", this.Fans) + `,`,
+		`Updated:` + fmt.Sprintf("%v", this.Updated) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Neighbor) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Neighbor{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`Count:` + fmt.Sprintf("%v", this.Count) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ALSModel) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ALSModel{`,
+		`Factors:` + fmt.Sprintf("%v", this.Factors) + `,`,
+		`Lambda:` + fmt.Sprintf("%v", this.Lambda) + `,`,
+		`Alpha:` + fmt.Sprintf("%v", this.Alpha) + `,`,
+		`Yty:` + fmt.Sprintf("%v", this.Yty) + `,`,
+		`Trained:` + fmt.Sprintf("%v", this.Trained) + `,`,
+		`Stories:` + fmt.Sprintf("%v", this.Stories) + `,`,
+		`Users:` + fmt.Sprintf("%v", this.Users) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Factors) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Factors{`,
+		`Values:` + fmt.Sprintf("%v", this.Values) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1216,6 +2060,113 @@ func (m *User) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FavSeen", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMain
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMain
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.FavSeen == nil {
+				m.FavSeen = make(map[string]int64)
+			}
+			var mapkey string
+			var mapvalue int64
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMain
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMain
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthMain
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMain
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapvalue |= (int64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipMain(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthMain
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.FavSeen[mapkey] = mapvalue
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMain(dAtA[iNdEx:])
@@ -1672,23 +2623,580 @@ func (m *Story) Unmarshal(dAtA []byte) error {
 			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
 			iNdEx += 4
 			m.Score = float32(math.Float32frombits(v))
-		default:
-			iNdEx = preIndex
-			skippy, err := skipMain(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthMain
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
+		case 19:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthorId", wireType)
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMain
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMain
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AuthorId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 20:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthorName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMain
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMain
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AuthorName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMain(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMain
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Neighbors) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMain
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Neighbors: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Neighbors: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Neighbors", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMain
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMain
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Neighbors = append(m.Neighbors, &Neighbor{})
+			if err := m.Neighbors[len(m.Neighbors)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Fans", wireType)
+			}
+			m.Fans = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMain
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Fans |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Updated", wireType)
+			}
+			m.Updated = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMain
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Updated |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMain(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMain
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Neighbor) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMain
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Neighbor: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Neighbor: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMain
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMain
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Count", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Count = float32(math.Float32frombits(v))
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMain(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMain
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ALSModel) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMain
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ALSModel: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ALSModel: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Factors", wireType)
+			}
+			m.Factors = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMain
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Factors |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Lambda", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Lambda = float32(math.Float32frombits(v))
+		case 3:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Alpha", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Alpha = float32(math.Float32frombits(v))
+		case 4:
+			if wireType == 1 {
+				var v uint64
+				if (iNdEx + 8) > l {
+					return io.ErrUnexpectedEOF
+				}
+				v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+				iNdEx += 8
+				v2 := float64(math.Float64frombits(v))
+				m.Yty = append(m.Yty, v2)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMain
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthMain
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					if (iNdEx + 8) > l {
+						return io.ErrUnexpectedEOF
+					}
+					v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+					iNdEx += 8
+					v2 := float64(math.Float64frombits(v))
+					m.Yty = append(m.Yty, v2)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Yty", wireType)
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Trained", wireType)
+			}
+			m.Trained = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMain
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Trained |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Stories", wireType)
+			}
+			m.Stories = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMain
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Stories |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Users", wireType)
+			}
+			m.Users = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMain
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Users |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMain(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMain
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Factors) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMain
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Factors: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Factors: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType == 5 {
+				var v uint32
+				if (iNdEx + 4) > l {
+					return io.ErrUnexpectedEOF
+				}
+				v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+				iNdEx += 4
+				v2 := float32(math.Float32frombits(v))
+				m.Values = append(m.Values, v2)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMain
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthMain
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v uint32
+					if (iNdEx + 4) > l {
+						return io.ErrUnexpectedEOF
+					}
+					v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+					iNdEx += 4
+					v2 := float32(math.Float32frombits(v))
+					m.Values = append(m.Values, v2)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMain(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMain
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
 		return io.ErrUnexpectedEOF
 	}
 	return nil
@@ -1798,39 +3306,54 @@ var (
 	ErrIntOverflowMain   = fmt.Errorf("proto: integer overflow")
 )
 
-func init() { proto.RegisterFile("main.proto", fileDescriptor_main_902b1cd6a44eeb55) }
-
-var fileDescriptor_main_902b1cd6a44eeb55 = []byte{
-	// 496 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x53, 0xb1, 0x6e, 0xdb, 0x3a,
-	0x14, 0x35, 0x6d, 0xc9, 0xb6, 0x6e, 0xf2, 0xfc, 0x54, 0xa2, 0x28, 0x98, 0xa2, 0x65, 0x85, 0x4c,
-	0x42, 0x11, 0x64, 0x68, 0xbe, 0x20, 0x09, 0x62, 0x20, 0x4b, 0x52, 0x48, 0xc9, 0x6c, 0xd0, 0x16,
-	0x9d, 0x10, 0x90, 0x4c, 0x83, 0xa4, 0x94, 0x7a, 0xeb, 0x27, 0xf4, 0x33, 0xfa, 0x29, 0x45, 0xa7,
-	0x0c, 0x1d, 0x3a, 0xd6, 0xea, 0xd2, 0x31, 0x9f, 0x50, 0x90, 0x8a, 0xd5, 0x18, 0xed, 0xa4, 0x7b,
-	0xce, 0xb9, 0x24, 0x75, 0xee, 0x21, 0x01, 0x0a, 0x26, 0x16, 0x87, 0x4b, 0x25, 0x8d, 0xdc, 0xff,
-	0x86, 0xc0, 0xbb, 0xd6, 0x5c, 0xe1, 0x11, 0x74, 0x45, 0x46, 0x50, 0x84, 0xe2, 0x20, 0xe9, 0x8a,
-	0x0c, 0xbf, 0x80, 0x3e, 0xff, 0x20, 0xb4, 0xd1, 0xa4, 0x1b, 0xa1, 0x78, 0x98, 0x3c, 0x22, 0x8c,
-	0xc1, 0x5b, 0xb0, 0x82, 0x93, 0x9e, 0xeb, 0x74, 0x35, 0x26, 0x30, 0xd0, 0x46, 0x2a, 0xc1, 0x35,
-	0xf1, 0xa2, 0x5e, 0x1c, 0x24, 0x1b, 0x88, 0xdf, 0xc0, 0xce, 0x9c, 0x55, 0x93, 0x8d, 0xea, 0x3b,
-	0x15, 0xe6, 0xac, 0x4a, 0xb7, 0x1b, 0x58, 0x69, 0x6e, 0xa5, 0xd2, 0xa4, 0xdf, 0x36, 0x1c, 0x37,
-	0x0c, 0xde, 0x83, 0xe1, 0x9c, 0x55, 0x3c, 0x9b, 0x4c, 0x57, 0x64, 0xd0, 0x6c, 0xee, 0xf0, 0xc9,
-	0x0a, 0xef, 0x81, 0xa7, 0x85, 0xe1, 0x64, 0x18, 0xa1, 0x78, 0xf4, 0xce, 0x3f, 0x4c, 0x85, 0xe1,
-	0x89, 0xa3, 0xf6, 0xbf, 0xf6, 0xc0, 0xb7, 0x47, 0xac, 0x9e, 0xf8, 0xf2, 0x9d, 0xaf, 0xe7, 0xe0,
-	0x1b, 0x61, 0x72, 0xee, 0x6c, 0x05, 0x49, 0x03, 0xf0, 0x4b, 0x18, 0xce, 0x98, 0xe1, 0x37, 0x52,
-	0xad, 0x1e, 0x9d, 0xb5, 0xd8, 0xae, 0x10, 0x05, 0xbb, 0xe1, 0xc4, 0x6b, 0x56, 0x38, 0x60, 0xe7,
-	0x90, 0x71, 0x3d, 0x23, 0x7e, 0x33, 0x07, 0x5b, 0xe3, 0x10, 0x7a, 0xa5, 0xca, 0x49, 0xdf, 0x51,
-	0xb6, 0xb4, 0xa7, 0x67, 0x39, 0x19, 0x34, 0x53, 0xcd, 0x72, 0xfc, 0x1a, 0xe0, 0x4e, 0xaa, 0x6c,
-	0x32, 0x93, 0xe5, 0xc2, 0xb8, 0x1f, 0xf7, 0x93, 0xc0, 0x32, 0xa7, 0x96, 0xb0, 0xd3, 0xc8, 0x98,
-	0xe1, 0x13, 0x5d, 0x4e, 0x0b, 0x61, 0x48, 0xe0, 0x74, 0xb0, 0x54, 0xea, 0x98, 0xb6, 0xa1, 0x5c,
-	0xda, 0x0f, 0x81, 0x3f, 0x0d, 0xd7, 0x8e, 0xb1, 0x51, 0x28, 0x5e, 0x09, 0x7e, 0xa7, 0xc9, 0x8e,
-	0x13, 0x37, 0xd0, 0x59, 0xbc, 0x65, 0x4b, 0xc3, 0x95, 0x26, 0xbb, 0x4e, 0x6a, 0xb1, 0xd3, 0x64,
-	0xb1, 0xcc, 0xb9, 0xe1, 0xe4, 0x3f, 0x17, 0x77, 0x8b, 0xb7, 0x02, 0x18, 0xfd, 0x3b, 0x80, 0xff,
-	0xff, 0x0a, 0xe0, 0xc9, 0xf5, 0x09, 0xb7, 0xae, 0xcf, 0x2b, 0x08, 0xe6, 0xac, 0x92, 0x4a, 0x18,
-	0xae, 0xc9, 0xb3, 0xc6, 0x7f, 0x4b, 0xd8, 0x51, 0xeb, 0x99, 0x54, 0x9c, 0xe0, 0x08, 0xc5, 0xdd,
-	0xa4, 0x01, 0x6f, 0x0f, 0xc0, 0xb3, 0x3b, 0xe3, 0x00, 0xfc, 0xf1, 0xf8, 0xe2, 0xec, 0x2a, 0xec,
-	0xe0, 0x01, 0xf4, 0x8e, 0x2f, 0x8f, 0x42, 0x84, 0x43, 0xd8, 0x1d, 0x9f, 0x9f, 0x5e, 0x9d, 0x5f,
-	0x5e, 0xbc, 0x4f, 0xce, 0xd2, 0x34, 0xec, 0x9e, 0x1c, 0xdc, 0xaf, 0x69, 0xe7, 0xfb, 0x9a, 0x76,
-	0x1e, 0xd6, 0x14, 0x7d, 0xac, 0x29, 0xfa, 0x5c, 0x53, 0xf4, 0xa5, 0xa6, 0xe8, 0xbe, 0xa6, 0xe8,
-	0x47, 0x4d, 0xd1, 0xaf, 0x9a, 0x76, 0x1e, 0x6a, 0x8a, 0x3e, 0xfd, 0xa4, 0x9d, 0x69, 0xdf, 0x3d,
-	0x83, 0xa3, 0xdf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x21, 0xb7, 0xe5, 0xaf, 0x14, 0x03, 0x00, 0x00,
+func init() { proto.RegisterFile("main.proto", fileDescriptor_main_a6cdd95900528566) }
+
+var fileDescriptor_main_a6cdd95900528566 = []byte{
+	// 736 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x94, 0x31, 0x6f, 0xdb, 0x38,
+	0x14, 0xc7, 0x4d, 0xc9, 0xb2, 0xad, 0x97, 0x5c, 0xce, 0xc7, 0x0b, 0x0e, 0x4c, 0xee, 0x4e, 0x75,
+	0xbd, 0xd4, 0x28, 0x52, 0x0f, 0xc9, 0x52, 0x64, 0x4b, 0x82, 0x18, 0x08, 0xd0, 0x3a, 0x05, 0x9d,
+	0xcc, 0x06, 0x6d, 0xd1, 0xb1, 0x50, 0x59, 0x32, 0x24, 0xca, 0xa9, 0xb6, 0x8e, 0x1d, 0xfb, 0x31,
+	0x3a, 0xf5, 0x2b, 0x74, 0xed, 0x98, 0xb1, 0x63, 0xe3, 0x2e, 0x1d, 0xf3, 0x11, 0x0a, 0x3e, 0x4a,
+	0x76, 0x82, 0x66, 0x12, 0x7f, 0xef, 0x91, 0x8f, 0xe4, 0xff, 0xfd, 0x29, 0x80, 0x99, 0x08, 0xa2,
+	0xee, 0x3c, 0x89, 0x55, 0xdc, 0xfe, 0x62, 0x41, 0xf5, 0x32, 0x95, 0x09, 0xdd, 0x02, 0x2b, 0xf0,
+	0x19, 0x69, 0x91, 0x8e, 0xcb, 0xad, 0xc0, 0xa7, 0xff, 0x40, 0x4d, 0xbe, 0x0b, 0x52, 0x95, 0x32,
+	0xab, 0x45, 0x3a, 0x0d, 0x5e, 0x10, 0xa5, 0x50, 0x8d, 0xc4, 0x4c, 0x32, 0x1b, 0x67, 0xe2, 0x98,
+	0x32, 0xa8, 0xa7, 0x2a, 0x4e, 0x02, 0x99, 0xb2, 0x6a, 0xcb, 0xee, 0xb8, 0xbc, 0x44, 0xfa, 0x04,
+	0x36, 0x26, 0x62, 0x31, 0x2c, 0xb3, 0x0e, 0x66, 0x61, 0x22, 0x16, 0x83, 0x87, 0x13, 0x44, 0xa6,
+	0xa6, 0x71, 0x92, 0xb2, 0xda, 0x6a, 0xc2, 0x91, 0x89, 0xd0, 0x1d, 0x68, 0x4c, 0xc4, 0x42, 0xfa,
+	0xc3, 0x51, 0xce, 0xea, 0xa6, 0x38, 0xf2, 0x71, 0x4e, 0x77, 0xa0, 0x9a, 0x06, 0x4a, 0xb2, 0x46,
+	0x8b, 0x74, 0xb6, 0xf6, 0x9d, 0xee, 0x20, 0x50, 0x92, 0x63, 0x88, 0xbe, 0xc0, 0x55, 0xc3, 0x54,
+	0xca, 0x88, 0xb9, 0x2d, 0xbb, 0xb3, 0xb1, 0x4f, 0xbb, 0xfa, 0x9a, 0xdd, 0x9e, 0x58, 0x0c, 0xa4,
+	0x8c, 0x4e, 0x23, 0x95, 0xe4, 0x58, 0x49, 0xd3, 0xee, 0x21, 0x6c, 0xde, 0x4f, 0xd0, 0x26, 0xd8,
+	0x6f, 0x65, 0x5e, 0xa8, 0xa1, 0x87, 0x74, 0x1b, 0x9c, 0x85, 0x08, 0x33, 0x89, 0x6a, 0xd8, 0xdc,
+	0xc0, 0xa1, 0xf5, 0x92, 0xb4, 0x3f, 0x54, 0xc1, 0xd1, 0xb7, 0xc9, 0xef, 0x49, 0xe8, 0xa0, 0x84,
+	0xdb, 0xe0, 0xa8, 0x40, 0x85, 0x66, 0x8d, 0xcb, 0x0d, 0xd0, 0x5d, 0x68, 0x8c, 0x85, 0x92, 0x57,
+	0x71, 0x92, 0x17, 0x22, 0xae, 0x58, 0xaf, 0x08, 0x66, 0xe2, 0x4a, 0xb2, 0xaa, 0x59, 0x81, 0xa0,
+	0x25, 0xf7, 0x65, 0x3a, 0x66, 0x8e, 0x91, 0x5c, 0x8f, 0xf5, 0x09, 0xb3, 0x24, 0x64, 0x35, 0x73,
+	0xc2, 0x2c, 0x09, 0xf5, 0xee, 0x7e, 0xc8, 0xea, 0xa6, 0x81, 0x7e, 0x48, 0xff, 0x07, 0xb8, 0x8e,
+	0x13, 0x7f, 0x38, 0x8e, 0xb3, 0x48, 0xa1, 0x46, 0x0e, 0x77, 0x75, 0xe4, 0x44, 0x07, 0xb4, 0xf0,
+	0xbe, 0x50, 0x72, 0x98, 0x66, 0xa3, 0x59, 0xa0, 0x98, 0x8b, 0x79, 0xd0, 0xa1, 0x01, 0x46, 0x56,
+	0x13, 0xb2, 0xb9, 0xfe, 0x30, 0x58, 0x4f, 0xb8, 0xc4, 0x88, 0xee, 0x7a, 0x22, 0x17, 0x81, 0xbc,
+	0x4e, 0xd9, 0x06, 0x26, 0x4b, 0xc4, 0x2b, 0x4e, 0xc5, 0x5c, 0xc9, 0x24, 0x65, 0x9b, 0x98, 0x5a,
+	0x31, 0xe6, 0xe2, 0xd9, 0x3c, 0x94, 0x4a, 0xb2, 0x3f, 0xd0, 0x59, 0x2b, 0x7e, 0xd0, 0xeb, 0xad,
+	0xc7, 0x7b, 0xfd, 0xe7, 0xef, 0xbd, 0x5e, 0x3b, 0xb5, 0xf9, 0xc0, 0xa9, 0xff, 0x81, 0x3b, 0x11,
+	0x8b, 0x38, 0x09, 0x94, 0x4c, 0xd9, 0x5f, 0xe6, 0xfe, 0xab, 0x80, 0x96, 0x3a, 0x1d, 0xc7, 0x89,
+	0x64, 0xb4, 0x45, 0x3a, 0x16, 0x37, 0x40, 0xff, 0x05, 0xd7, 0x58, 0x71, 0x18, 0xf8, 0xec, 0x6f,
+	0xd3, 0x1d, 0x13, 0x38, 0xf3, 0xb5, 0x22, 0x45, 0x12, 0x5f, 0xc0, 0x36, 0xa6, 0xc1, 0x84, 0xfa,
+	0x62, 0x26, 0xdb, 0x23, 0x70, 0xfb, 0x32, 0xb8, 0x9a, 0x8e, 0xb4, 0x71, 0x9f, 0x81, 0x1b, 0x95,
+	0xc0, 0x08, 0x7a, 0xd0, 0xed, 0x96, 0x69, 0xbe, 0xce, 0xe9, 0xf6, 0x4e, 0x44, 0x64, 0xde, 0x99,
+	0xc3, 0x71, 0xac, 0xb5, 0x35, 0xba, 0xfb, 0xe8, 0x11, 0x9b, 0x97, 0xd8, 0xde, 0x87, 0x46, 0x59,
+	0xe4, 0x71, 0x9b, 0x9a, 0x7e, 0x5b, 0xe6, 0x56, 0x08, 0xed, 0xcf, 0x04, 0x1a, 0x47, 0xaf, 0x06,
+	0xaf, 0x63, 0x5f, 0x86, 0xba, 0xf4, 0x44, 0x8c, 0x95, 0x39, 0x15, 0xb6, 0xad, 0x40, 0x2d, 0x64,
+	0x28, 0x66, 0x23, 0x5f, 0x14, 0xab, 0x0b, 0xd2, 0x45, 0x45, 0x38, 0x9f, 0x0a, 0x3c, 0x8a, 0xc5,
+	0x0d, 0xe8, 0xcd, 0x73, 0x95, 0xe3, 0x83, 0x27, 0x5c, 0x0f, 0x75, 0x65, 0x95, 0x88, 0x20, 0x92,
+	0x3e, 0x5a, 0xd5, 0xe6, 0x25, 0xde, 0xff, 0x41, 0xd4, 0xcc, 0x9e, 0x05, 0xea, 0xda, 0x59, 0xaa,
+	0x7d, 0x52, 0xc7, 0xb8, 0x81, 0xf6, 0x53, 0xa8, 0xf7, 0xd6, 0x87, 0xc2, 0xb7, 0x66, 0x34, 0xb4,
+	0x78, 0x41, 0xcf, 0xf7, 0xa0, 0xaa, 0x3d, 0x40, 0x5d, 0x70, 0x7a, 0xbd, 0xfe, 0xe9, 0x45, 0xb3,
+	0x42, 0xeb, 0x60, 0x1f, 0x9d, 0x1f, 0x34, 0x09, 0x6d, 0xc2, 0x66, 0xef, 0xec, 0xe4, 0xe2, 0xec,
+	0xbc, 0xff, 0x86, 0x9f, 0x0e, 0x06, 0x4d, 0xeb, 0x78, 0xef, 0xe6, 0xd6, 0xab, 0x7c, 0xbb, 0xf5,
+	0x2a, 0x77, 0xb7, 0x1e, 0x79, 0xbf, 0xf4, 0xc8, 0xa7, 0xa5, 0x47, 0xbe, 0x2e, 0x3d, 0x72, 0xb3,
+	0xf4, 0xc8, 0xf7, 0xa5, 0x47, 0x7e, 0x2e, 0xbd, 0xca, 0xdd, 0xd2, 0x23, 0x1f, 0x7f, 0x78, 0x95,
+	0x51, 0x0d, 0xff, 0x8d, 0x07, 0xbf, 0x06, 0x00, 0x76, 0x0c, 0x2f, 0xf4, 0x29, 0x05, 0x00, 0x00,
 }
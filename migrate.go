@@ -0,0 +1,373 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var migrateDryRun = flag.Bool("migrate_dry_run", false, "report what the migrate command would change without writing")
+
+// The schema version of the stored records is kept under schemaVersionKey. A
+// running migration saves its position under migrationProgressKey as
+// <version>|<prefix>|<last key> after every batch so it can resume.
+const (
+	schemaVersionKey     = "schema-version"
+	migrationProgressKey = "migration-progress"
+	migrateBatchSize     = 1000
+)
+
+// migration upgrades the stored records to a schema version. Story and User
+// are called with each record, either can be nil, and return whether the record
+// has to be rewritten. They can write other keys with txn.
+type migration struct {
+	Version int
+	Name    string
+	Story   func(txn Txn, st *Story) (bool, error)
+	User    func(txn Txn, u *User) (bool, error)
+}
+
+var migrations []migration
+
+// registerMigration adds a migration. Migrations have to be registered in
+// version order.
+func registerMigration(m migration) {
+	if len(migrations) > 0 && m.Version <= migrations[len(migrations)-1].Version {
+		log.Fatalf("migration %d %q registered out of order", m.Version, m.Name)
+	}
+	migrations = append(migrations, m)
+}
+
+// latestSchemaVersion is the version of the last registered migration.
+func latestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+func init() {
+	registerMigration(migration{
+		Version: 1,
+		Name:    "backfill story favorites",
+		Story: func(txn Txn, st *Story) (bool, error) {
+			// Records written before favorites was scraped only have their fans.
+			if int(st.Favorites) >= len(st.FavedBy) {
+				return false, nil
+			}
+			st.Favorites = int32(len(st.FavedBy))
+			return true, nil
+		},
+	})
+	registerMigration(migration{
+		Version: 2,
+		Name:    "index favorite authors",
+		User: func(txn Txn, u *User) (bool, error) {
+			for _, author := range u.FavAuthors {
+				if err := setEdge(txn, favAuthorPrefix, User{Id: author, Site: u.Site}.key(), u.key(), nil); err != nil {
+					return false, err
+				}
+			}
+			return false, nil
+		},
+	})
+	registerMigration(migration{
+		Version: 3,
+		Name:    "index stories by category and author",
+		Story: func(txn Txn, st *Story) (bool, error) {
+			return false, indexStory(txn, nil, st)
+		},
+	})
+	registerMigration(migration{
+		Version: 4,
+		Name:    "backfill story authors from twins",
+		Story: func(txn Txn, st *Story) (bool, error) {
+			if st.AuthorId != "" {
+				return false, nil
+			}
+			author, err := storyAuthor(txn, st.key())
+			if err != nil || author == "" {
+				return false, err
+			}
+			parts := strings.SplitN(author, ":", 3)
+			if len(parts) != 3 {
+				return false, nil
+			}
+			st.AuthorId = parts[2]
+			if u, err := getUser(txn, author); err == nil {
+				st.AuthorName = u.Name
+			} else if err != errNotFound {
+				return false, err
+			}
+			return true, nil
+		},
+	})
+}
+
+// schemaVersion returns the stored schema version, 0 if there's none.
+func (s *server) schemaVersion() (int, error) {
+	version := 0
+	err := s.db.View(func(txn Txn) error {
+		val, err := txn.Get(schemaVersionKey)
+		if err == errNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		version, err = strconv.Atoi(string(val))
+		return err
+	})
+	return version, err
+}
+
+// checkSchema marks new databases as up to date and warns if the database
+// needs to be migrated.
+func (s *server) checkSchema() error {
+	version, err := s.schemaVersion()
+	if err != nil {
+		return err
+	}
+	latest := latestSchemaVersion()
+	if version > latest {
+		return errors.Errorf("database schema version %d is newer than %d", version, latest)
+	}
+	if version == latest {
+		return nil
+	}
+	empty := true
+	if err := s.db.View(func(txn Txn) error {
+		for _, prefix := range []string{"story:", "user:"} {
+			if err := txn.Scan(prefix, false, func(string, []byte) error {
+				empty = false
+				return errStopScan
+			}); err != nil || !empty {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if empty {
+		return s.db.Update(func(txn Txn) error {
+			return txn.Set(schemaVersionKey, []byte(strconv.Itoa(latest)))
+		})
+	}
+	log.Printf("database schema version %d is older than %d, run the migrate command", version, latest)
+	return nil
+}
+
+// dryRunTxn drops writes.
+type dryRunTxn struct {
+	Txn
+}
+
+func (dryRunTxn) Set(key string, value []byte) error { return nil }
+func (dryRunTxn) Delete(key string) error            { return nil }
+
+// cmdMigrate runs the migrations newer than the stored schema version.
+func (s *server) cmdMigrate() error {
+	version, err := s.schemaVersion()
+	if err != nil {
+		return err
+	}
+	if version > latestSchemaVersion() {
+		return errors.Errorf("database schema version %d is newer than %d", version, latestSchemaVersion())
+	}
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+		if err := s.runMigration(m); err != nil {
+			return errors.Wrapf(err, "migration %d %q", m.Version, m.Name)
+		}
+	}
+	if !*migrateDryRun {
+		log.Printf("Database is at schema version %d", latestSchemaVersion())
+	}
+	return nil
+}
+
+// migrationProgress returns the prefix and last key migrated by an interrupted
+// run of the migration.
+func (s *server) migrationProgress(version int) (prefix, last string, err error) {
+	err = s.db.View(func(txn Txn) error {
+		val, err := txn.Get(migrationProgressKey)
+		if err == errNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		parts := strings.SplitN(string(val), "|", 3)
+		if len(parts) != 3 || parts[0] != strconv.Itoa(version) {
+			return nil
+		}
+		prefix, last = parts[1], parts[2]
+		return nil
+	})
+	return prefix, last, err
+}
+
+func (s *server) runMigration(m migration) error {
+	start := time.Now()
+	log.Printf("Running migration %d %q (dry run %t)", m.Version, m.Name, *migrateDryRun)
+	resumePrefix, last, err := s.migrationProgress(m.Version)
+	if err != nil {
+		return err
+	}
+	steps := []struct {
+		prefix string
+		fn     func(txn Txn, key string) (bool, error)
+	}{
+		{"story:", nil},
+		{"user:", nil},
+	}
+	if m.Story != nil {
+		steps[0].fn = func(txn Txn, key string) (bool, error) {
+			st, err := getStory(txn, key)
+			if err != nil {
+				return false, err
+			}
+			changed, err := m.Story(txn, st)
+			if err != nil || !changed {
+				return false, err
+			}
+			return true, setStory(txn, *st)
+		}
+	}
+	if m.User != nil {
+		steps[1].fn = func(txn Txn, key string) (bool, error) {
+			u, err := getUser(txn, key)
+			if err != nil {
+				return false, err
+			}
+			changed, err := m.User(txn, u)
+			if err != nil || !changed {
+				return false, err
+			}
+			body, err := u.Marshal()
+			if err != nil {
+				return false, err
+			}
+			return true, txn.Set(key, body)
+		}
+	}
+
+	resuming := resumePrefix != ""
+	for _, step := range steps {
+		from := step.prefix
+		if resuming {
+			// The keyspaces before the interrupted one are done.
+			if step.prefix != resumePrefix {
+				continue
+			}
+			resuming = false
+			from = last + "\x00"
+			log.Printf("Resuming %s after %q", step.prefix, last)
+		}
+		if step.fn == nil {
+			continue
+		}
+		if err := s.migratePrefix(m, step.prefix, from, step.fn); err != nil {
+			return err
+		}
+	}
+
+	if *migrateDryRun {
+		log.Printf("Dry run of migration %d took %s", m.Version, time.Since(start))
+		return nil
+	}
+	if err := s.db.Update(func(txn Txn) error {
+		if err := txn.Set(schemaVersionKey, []byte(strconv.Itoa(m.Version))); err != nil {
+			return err
+		}
+		return txn.Delete(migrationProgressKey)
+	}); err != nil {
+		return err
+	}
+	log.Printf("Migration %d took %s", m.Version, time.Since(start))
+	return nil
+}
+
+// migratePrefix applies fn to the records under prefix starting at from, in
+// batches, saving the progress after each batch. Batches whose writes are too
+// big for a transaction are split.
+func (s *server) migratePrefix(m migration, prefix, from string, fn func(txn Txn, key string) (bool, error)) error {
+	total := 0
+	if err := s.db.View(func(txn Txn) error {
+		return txn.ScanFrom(prefix, from, false, func(string, []byte) error {
+			total++
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+	done, changed := 0, 0
+	for {
+		var keys []string
+		if err := s.db.View(func(txn Txn) error {
+			return txn.ScanFrom(prefix, from, false, func(key string, _ []byte) error {
+				if len(keys) >= migrateBatchSize {
+					return errStopScan
+				}
+				keys = append(keys, key)
+				return nil
+			})
+		}); err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+
+		var last string
+		batchChanged := 0
+		apply := func(txn Txn) error {
+			batchChanged = 0
+			for _, key := range keys {
+				ok, err := fn(txn, key)
+				if err == errNotFound {
+					continue
+				} else if err != nil {
+					return errors.Wrapf(err, "migrating %s", key)
+				}
+				if ok {
+					batchChanged++
+				}
+			}
+			if *migrateDryRun {
+				return nil
+			}
+			return txn.Set(migrationProgressKey, []byte(fmt.Sprintf("%d|%s|%s", m.Version, prefix, last)))
+		}
+		var err error
+		for {
+			last = keys[len(keys)-1]
+			if *migrateDryRun {
+				err = s.db.View(func(txn Txn) error {
+					return apply(dryRunTxn{txn})
+				})
+			} else {
+				err = s.updateRetry(apply)
+			}
+			// Retry with half the batch if its writes don't fit in a transaction.
+			if errors.Cause(err) != errTxnTooBig || len(keys) == 1 {
+				break
+			}
+			keys = keys[:len(keys)/2]
+		}
+		if err != nil {
+			return err
+		}
+
+		done += len(keys)
+		changed += batchChanged
+		log.Printf("Migration %d: %s %d/%d records, %d changed", m.Version, prefix, done, total, changed)
+		from = last + "\x00"
+	}
+}
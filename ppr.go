@@ -0,0 +1,222 @@
+package main
+
+import (
+	"flag"
+	"hash/fnv"
+	"log"
+	"math/rand"
+	"time"
+)
+
+var (
+	pprSteps   = flag.Int("ppr_steps", 20000, "random walk step budget of the ppr recommender")
+	pprRestart = flag.Float64("ppr_restart", 0.15, "probability of the ppr random walk restarting at a seed")
+)
+
+func init() {
+	registerRecommender(pprRecommender{})
+}
+
+// pprRecommender ranks stories by personalized PageRank over the bipartite
+// user-story graph, estimated with random walks that restart at the seeds.
+// From a story the walk moves to one of its fans, from a fan to one of their
+// favorite stories or authors, and from an author to one of their stories.
+type pprRecommender struct{}
+
+func (pprRecommender) Name() string { return "ppr" }
+
+func (pprRecommender) Match(s *server, urls []string) ([]seed, error) {
+	return matchStoryURLs(s, urls)
+}
+
+// pprGraph lazily loads the nodes visited by a walk.
+type pprGraph struct {
+	s       *server
+	stories map[string]*Story
+	users   map[string]*User
+	// ignore is a user the walk never visits.
+	ignore string
+}
+
+func (g *pprGraph) story(key string) (*Story, error) {
+	if st, ok := g.stories[key]; ok {
+		return st, nil
+	}
+	st, err := g.s.storyByKey(key)
+	if err == errNotFound {
+		g.stories[key] = nil
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	g.stories[key] = &st
+	return &st, nil
+}
+
+func (g *pprGraph) user(key string) (*User, error) {
+	if u, ok := g.users[key]; ok {
+		return u, nil
+	}
+	u, err := g.s.userByKey(key)
+	if err == errNotFound {
+		g.users[key] = nil
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	g.users[key] = &u
+	return &u, nil
+}
+
+func (pprRecommender) Recommend(s *server, seeds []seed, opts recOptions) (recResp, error) {
+	start := time.Now()
+	g := &pprGraph{
+		s:       s,
+		stories: make(map[string]*Story),
+		users:   make(map[string]*User),
+		ignore:  opts.IgnoreUser,
+	}
+
+	// Seed the random source from the seeds so pages are consistent.
+	h := fnv.New64a()
+	isSeed := make(map[string]bool, len(seeds))
+	var walkSeeds []seed
+	totalWeight := 0.0
+	for _, seed := range seeds {
+		h.Write([]byte(seed.Key))
+		isSeed[seed.Key] = true
+		if seed.Weight > 0 {
+			walkSeeds = append(walkSeeds, seed)
+			totalWeight += seed.Weight
+		}
+	}
+	if len(walkSeeds) == 0 {
+		return recResp{}, errStoryNotFound
+	}
+	r := rand.New(rand.NewSource(int64(h.Sum64())))
+	pickSeed := func() string {
+		x := r.Float64() * totalWeight
+		for _, seed := range walkSeeds {
+			x -= seed.Weight
+			if x < 0 {
+				return seed.Key
+			}
+		}
+		return walkSeeds[len(walkSeeds)-1].Key
+	}
+
+	visits := make(map[string]float64)
+	stats := make(map[string]*seedStats, len(walkSeeds))
+	for _, seed := range walkSeeds {
+		stats[seed.Key] = &seedStats{Weight: seed.Weight}
+	}
+
+	origin := pickSeed()
+	current := origin
+	for step := 0; step < *pprSteps; step++ {
+		st, err := g.story(current)
+		if err != nil {
+			return recResp{}, err
+		}
+		if st == nil || len(st.FavedBy) == 0 || r.Float64() < *pprRestart {
+			origin = pickSeed()
+			current = origin
+			continue
+		}
+		if !isSeed[current] {
+			visits[current]++
+			stats[origin].Favorites++
+		}
+
+		next, err := pprNextStory(g, r, st.FavedBy[r.Intn(len(st.FavedBy))])
+		if err != nil {
+			return recResp{}, err
+		}
+		if next == "" {
+			origin = pickSeed()
+			current = origin
+			continue
+		}
+		current = next
+	}
+
+	if len(opts.Negative) > 0 {
+		if err := s.penalizeNegative(visits, opts.Negative); err != nil {
+			return recResp{}, err
+		}
+	}
+
+	favorites := 0
+	for key, count := range visits {
+		favorites += int(count)
+		visits[key] = count / float64(*pprSteps)
+	}
+
+	var seedStories []*Story
+	var seedList []*seedStats
+	for _, seed := range walkSeeds {
+		st, err := g.story(seed.Key)
+		if err != nil {
+			return recResp{}, err
+		}
+		st.annotate()
+		ss := stats[seed.Key]
+		ss.Story = st
+		ss.Users = len(st.FavedBy)
+		seedStories = append(seedStories, st)
+		seedList = append(seedList, ss)
+	}
+
+	rsl, err := s.rankStories(visits, opts)
+	if err != nil {
+		return recResp{}, err
+	}
+	sOut, err := s.pageStories(rsl, visits, opts)
+	if err != nil {
+		return recResp{}, err
+	}
+	for _, st := range sOut {
+		st.annotate()
+		st.Score = float32(visits[st.key()])
+	}
+	resp := recResp{
+		Stories: sOut,
+		Story:   seedStories[0],
+		Seeds:   seedList,
+		Cursor:  nextCursor(sOut, visits, opts),
+		Stats: respStats{
+			StoryCount: len(visits),
+			Favorites:  favorites,
+			Users:      len(g.users),
+		},
+	}
+	log.Printf("pprRecommender(%q) took %s, stats %+v", seedStories[0].Title, time.Since(start), resp.Stats)
+	return resp, nil
+}
+
+// pprNextStory walks from the fan to one of their favorite stories, or through
+// one of their favorite authors to a story the author wrote. It returns "" if
+// the walk hit a dead end.
+func pprNextStory(g *pprGraph, r *rand.Rand, fanKey string) (string, error) {
+	if fanKey == g.ignore {
+		return "", nil
+	}
+	fan, err := g.user(fanKey)
+	if err != nil || fan == nil {
+		return "", err
+	}
+	n := len(fan.FavStories) + len(fan.FavAuthors)
+	if n == 0 {
+		return "", nil
+	}
+	i := r.Intn(n)
+	if i < len(fan.FavStories) {
+		return fan.FavStories[i], nil
+	}
+	authorKey := User{Id: fan.FavAuthors[i-len(fan.FavStories)], Site: fan.Site}.key()
+	author, err := g.user(authorKey)
+	if err != nil || author == nil || len(author.Stories) == 0 {
+		return "", err
+	}
+	return author.Stories[r.Intn(len(author.Stories))], nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// rankStories orders the candidates by score, after penalizing popular
+// stories by opts.Novelty and adjusting for opts.Recency. Only the candidates
+// after opts.After and up to the requested page are ranked, unless they have
+// to be filtered.
+func (s *server) rankStories(scores map[string]float64, opts recOptions) ([]string, error) {
+	if err := s.adjustScores(scores, opts); err != nil {
+		return nil, err
+	}
+	k := -1
+	if opts.Filter.empty() {
+		k = opts.Offset + opts.Limit
+		if opts.Diversity > 0 && k < diversityPool {
+			k = diversityPool
+		}
+	}
+	return topK(scores, k, opts.After), nil
+}
+
+// adjustScores penalizes popular stories by opts.Novelty and adjusts the
+// scores for opts.Recency. Stories whose score drops to nothing are removed.
+func (s *server) adjustScores(scores map[string]float64, opts recOptions) error {
+	if opts.Novelty > 0 || opts.Recency.adjustsStories() {
+		keys := make([]string, 0, len(scores))
+		for key := range scores {
+			keys = append(keys, key)
+		}
+		now := time.Now()
+		if err := s.forStories(keys, func(key string, st *Story) {
+			factor := opts.Recency.storyFactor(st, now)
+			if opts.Novelty > 0 {
+				factor /= noveltyPenalty(st, opts.Novelty)
+			}
+			if factor == 0 {
+				delete(scores, key)
+				return
+			}
+			if score := scores[key]; score >= 0 {
+				scores[key] = score * factor
+			} else {
+				scores[key] = score / factor
+			}
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// noveltyPenalty is popularity^novelty.
+func noveltyPenalty(st *Story, novelty float64) float64 {
+	return math.Pow(float64(popularity(st)+1), novelty)
+}
+
+// popularity is the larger of the story's favorites and the number of fans in
+// the database.
+func popularity(st *Story) int {
+	pop := int(st.Favorites)
+	if len(st.FavedBy) > pop {
+		pop = len(st.FavedBy)
+	}
+	return pop
+}
+
+// abandonedAfter is how long a work in progress has to go without updates to
+// count as abandoned.
+const abandonedAfter = 365 * 24 * time.Hour
+
+// minFavoriteWeight is the weight of favorites that decayed the most or were
+// seen before first seen times were recorded.
+const minFavoriteWeight = 0.1
+
+// recency are the time decay options.
+type recency struct {
+	// UpdateHalfLife halves the score of stories every time this much passed
+	// since their last update. Zero disables it.
+	UpdateHalfLife time.Duration
+	// FavoriteHalfLife halves the weight of favorites every time this much
+	// passed since they were first seen. Zero disables it.
+	FavoriteHalfLife time.Duration
+	// Abandoned scales the score of abandoned works in progress by
+	// 1 + Abandoned, so -1 removes them and positive values prefer them.
+	Abandoned float64
+}
+
+func (r recency) adjustsStories() bool {
+	return r.UpdateHalfLife > 0 || r.Abandoned != 0
+}
+
+// storyFactor is the multiplier of the story's score.
+func (r recency) storyFactor(st *Story, now time.Time) float64 {
+	updated := st.DateUpdate
+	if updated == 0 {
+		updated = st.DateSubmit
+	}
+	age := now.Sub(time.Unix(int64(updated), 0))
+	factor := 1.0
+	if r.UpdateHalfLife > 0 && updated > 0 {
+		factor *= halfLife(age, r.UpdateHalfLife)
+	}
+	if r.Abandoned != 0 && !st.Complete && updated > 0 && age > abandonedAfter {
+		factor *= math.Max(0, 1+r.Abandoned)
+	}
+	return factor
+}
+
+// favoriteWeight is the weight of the user's favorite of story.
+func (r recency) favoriteWeight(u *User, story string, now time.Time) float64 {
+	if r.FavoriteHalfLife == 0 {
+		return 1
+	}
+	seen, ok := u.FavSeen[story]
+	if !ok {
+		return minFavoriteWeight
+	}
+	return math.Max(minFavoriteWeight, halfLife(now.Sub(time.Unix(seen, 0)), r.FavoriteHalfLife))
+}
+
+// parseRecency reads the update_halflife and fav_halflife query parameters in
+// days and the abandoned scale.
+func parseRecency(r *http.Request) (recency, error) {
+	var rec recency
+	for _, p := range []struct {
+		field string
+		dst   *time.Duration
+	}{
+		{"update_halflife", &rec.UpdateHalfLife},
+		{"fav_halflife", &rec.FavoriteHalfLife},
+	} {
+		val := r.FormValue(p.field)
+		if val == "" {
+			continue
+		}
+		days, err := strconv.ParseFloat(val, 64)
+		if err != nil || !isFinite(days) || days < 0 {
+			return recency{}, errors.Errorf("invalid %s: %q", p.field, val)
+		}
+		*p.dst = time.Duration(days * float64(24*time.Hour))
+	}
+	if val := r.FormValue("abandoned"); val != "" {
+		abandoned, err := strconv.ParseFloat(val, 64)
+		if err != nil || !isFinite(abandoned) || abandoned < -1 {
+			return recency{}, errors.Errorf("invalid abandoned: %q", val)
+		}
+		rec.Abandoned = abandoned
+	}
+	return rec, nil
+}
+
+// isFinite returns whether f is neither NaN nor infinite.
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+func halfLife(age, halfLife time.Duration) float64 {
+	if age < 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(halfLife))
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Recommender is a strategy for recommending stories.
+type Recommender interface {
+	// Name returns the name used to select the recommender via ?algo=.
+	Name() string
+	// Match resolves urls into seed stories. It returns errStoryNotFound if
+	// none of the urls refer to a known story.
+	Match(s *server, urls []string) ([]seed, error)
+	// Recommend returns recommendations for the seed stories.
+	Recommend(s *server, seeds []seed, opts recOptions) (recResp, error)
+}
+
+// seed is a story recommendations are based on. Seeds with a zero weight are
+// only left out of the results.
+type seed struct {
+	Key    string
+	Weight float64
+}
+
+// parseSeedURL splits the optional weight suffix off a seed url, e.g.
+// "https://www.fanfiction.net/s/123*2".
+func parseSeedURL(url string) (string, float64) {
+	i := strings.LastIndex(url, "*")
+	if i < 0 {
+		return url, 1
+	}
+	weight, err := strconv.ParseFloat(url[i+1:], 64)
+	if err != nil || weight <= 0 {
+		return url, 1
+	}
+	return url[:i], weight
+}
+
+// recOptions are the per request recommendation options.
+type recOptions struct {
+	Limit  int
+	Offset int
+	// Negative are the stories the reader disliked.
+	Negative []seed
+	Filter   storyFilter
+	// Diversity trades relevance for diversity, from 0 to 1.
+	Diversity float64
+	// Novelty is the exponent of the popularity penalty, 0 to disable.
+	Novelty float64
+	Recency recency
+	// CrossSite also seeds with the copies of the seeds posted on other sites.
+	CrossSite bool
+	// IgnoreUser is a user key whose favorites aren't used, e.g. the user
+	// being evaluated.
+	IgnoreUser string
+	// After continues the ranking after a cursor instead of Offset.
+	After *cursor
+}
+
+const defaultRecommender = "cooccurrence"
+
+var recommenders = map[string]Recommender{}
+
+func registerRecommender(r Recommender) {
+	if _, ok := recommenders[r.Name()]; ok {
+		panic("duplicate recommender: " + r.Name())
+	}
+	recommenders[r.Name()] = r
+}
+
+// storyURL matches story urls for a single site.
+type storyURL struct {
+	regex *regexp.Regexp
+	site  Site
+}
+
+var storyURLs []storyURL
+
+// userURL matches user profile urls for a single site.
+type userURL struct {
+	regex *regexp.Regexp
+	site  Site
+	// lower is whether user ids are stored lower case.
+	lower bool
+}
+
+var userURLs []userURL
+
+// matchStoryURLs returns the seed stories the urls refer to. A user profile
+// url expands to all of the user's favorite stories, and the stories they
+// wrote as zero weight seeds so they are left out of the results.
+func matchStoryURLs(s *server, urls []string) ([]seed, error) {
+	var matches []seed
+	seen := make(map[string]int)
+	add := func(key string, weight float64) {
+		if i, ok := seen[key]; ok {
+			matches[i].Weight += weight
+			return
+		}
+		seen[key] = len(matches)
+		matches = append(matches, seed{key, weight})
+	}
+
+	var written []string
+	for _, url := range urls {
+		url, weight := parseSeedURL(url)
+		if user, ok := matchUserURL(s, url); ok {
+			favs, err := s.existingKeys(user.FavStories)
+			if err != nil {
+				return nil, err
+			}
+			for _, key := range favs {
+				add(key, weight)
+			}
+			written = append(written, user.Stories...)
+			continue
+		}
+		for _, su := range storyURLs {
+			submatches := su.regex.FindStringSubmatch(url)
+			if len(submatches) != 2 {
+				continue
+			}
+			st := Story{
+				Id:   atoi(submatches[1]),
+				Site: su.site,
+			}
+			if st.checkExistsTitle(s) {
+				add(st.key(), weight)
+			}
+			break
+		}
+	}
+	written, err := s.existingKeys(written)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range written {
+		add(key, 0)
+	}
+	if len(matches) == 0 {
+		return nil, errStoryNotFound
+	}
+	return matches, nil
+}
+
+// matchUserURL returns the stored user the profile url refers to.
+func matchUserURL(s *server, url string) (User, bool) {
+	u, ok := parseUserURL(url)
+	if !ok {
+		return User{}, false
+	}
+	user, err := s.userByKey(u.key())
+	if err != nil {
+		return User{}, false
+	}
+	return user, true
+}
+
+// parseUserURL returns the user with the id and site of a profile url, without
+// loading it.
+func parseUserURL(url string) (User, bool) {
+	for _, uu := range userURLs {
+		submatches := uu.regex.FindStringSubmatch(url)
+		if len(submatches) != 2 {
+			continue
+		}
+		id := submatches[1]
+		if uu.lower {
+			id = strings.ToLower(id)
+		}
+		return User{
+			Id:   id,
+			Site: uu.site,
+		}, true
+	}
+	return User{}, false
+}
+
+// recommendations returns recommendations for the "|" separated story urls in
+// url, pushing down stories liked by the fans of the urls in neg.
+func (s *server) recommendations(url, neg, algo string, opts recOptions) (recResp, error) {
+	if algo == "" {
+		algo = defaultRecommender
+	}
+	rec, ok := recommenders[algo]
+	if !ok {
+		return recResp{}, errors.Errorf("unknown recommendation algorithm: %q", algo)
+	}
+	seeds, err := rec.Match(s, strings.Split(url, "|"))
+	if err != nil {
+		return recResp{}, err
+	}
+	if neg != "" {
+		opts.Negative, err = rec.Match(s, strings.Split(neg, "|"))
+		if err != nil && err != errStoryNotFound {
+			return recResp{}, err
+		}
+	}
+	if opts.CrossSite {
+		if seeds, err = s.expandTwins(seeds); err != nil {
+			return recResp{}, err
+		}
+		if opts.Negative, err = s.expandTwins(opts.Negative); err != nil {
+			return recResp{}, err
+		}
+	}
+	return rec.Recommend(s, seeds, opts)
+}
+
+func init() {
+	registerRecommender(cooccurrenceRecommender{name: "cooccurrence"})
+	registerRecommender(cooccurrenceRecommender{name: "jaccard", score: scoreJaccard})
+	registerRecommender(cooccurrenceRecommender{name: "cosine", score: scoreCosine})
+	registerRecommender(cooccurrenceRecommender{name: "lift", score: scoreLift})
+	registerRecommender(cooccurrenceRecommender{name: "pmi", score: scorePMI})
+}
+
+// cooccurrenceRecommender ranks stories by how many of the seed story's fans
+// also favorited them, optionally normalized by score.
+type cooccurrenceRecommender struct {
+	name string
+	// score is nil to rank by the raw co-favorite counts.
+	score scorer
+}
+
+func (r cooccurrenceRecommender) Name() string { return r.name }
+
+func (cooccurrenceRecommender) Match(s *server, urls []string) ([]seed, error) {
+	return matchStoryURLs(s, urls)
+}
+
+func (r cooccurrenceRecommender) Recommend(s *server, seeds []seed, opts recOptions) (recResp, error) {
+	return recommendationStory(s, r.name, seeds, r.score, opts)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// cooc describes how often a candidate story was favorited together with the
+// seed stories.
+type cooc struct {
+	// Count is the number of seed fans that favorited the candidate.
+	Count float64
+	// Seed is the number of seed fans.
+	Seed int
+	// Candidate is the number of users that favorited the candidate.
+	Candidate int
+	// Total is the number of users in the database.
+	Total int
+}
+
+// scorer normalizes a co-favorite count into a similarity score.
+type scorer func(c cooc) float64
+
+func scoreJaccard(c cooc) float64 {
+	union := float64(c.Seed+c.Candidate) - c.Count
+	if union <= 0 {
+		return 0
+	}
+	return c.Count / union
+}
+
+func scoreCosine(c cooc) float64 {
+	if c.Seed == 0 || c.Candidate == 0 {
+		return 0
+	}
+	return c.Count / math.Sqrt(float64(c.Seed)*float64(c.Candidate))
+}
+
+func scoreLift(c cooc) float64 {
+	if c.Seed == 0 || c.Candidate == 0 {
+		return 0
+	}
+	return c.Count * float64(c.Total) / (float64(c.Seed) * float64(c.Candidate))
+}
+
+func scorePMI(c cooc) float64 {
+	lift := scoreLift(c)
+	if lift <= 0 {
+		return 0
+	}
+	return math.Log(lift)
+}
+
+// scoreStories replaces the weighted co-favorite counts with normalized scores.
+// The scorer sees the number of seed fans that favorited each candidate and the
+// score is scaled by the mean weight of their favorites afterwards, so the seed
+// weights don't skew the set sizes the scorers compare.
+func (s *server) scoreStories(counts map[string]float64, shared map[string]int, seedFans int, score scorer) (map[string]float64, error) {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	coocs, err := s.coocs(shared, keys, seedFans)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[string]float64, len(counts))
+	for key, c := range coocs {
+		scores[key] = score(c) * meanWeight(counts[key], shared[key])
+	}
+	return scores, nil
+}
+
+// meanWeight is the mean weight of the shared fans' favorites of a candidate.
+func meanWeight(count float64, shared int) float64 {
+	if shared == 0 {
+		return 0
+	}
+	return count / float64(shared)
+}
+
+// coocs returns the inputs to a scorer for each of the keys.
+func (s *server) coocs(shared map[string]int, keys []string, seedFans int) (map[string]cooc, error) {
+	favedBy, err := s.favedByCounts(keys)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.userCount()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]cooc, len(keys))
+	for _, key := range keys {
+		out[key] = cooc{
+			Count:     float64(shared[key]),
+			Seed:      seedFans,
+			Candidate: favedBy[key],
+			Total:     total,
+		}
+	}
+	return out, nil
+}
+
+// favedByCounts returns len(FavedBy) of each story. Missing stories are
+// skipped.
+func (s *server) favedByCounts(keys []string) (map[string]int, error) {
+	counts := make(map[string]int, len(keys))
+	if err := s.forStories(keys, func(key string, st *Story) {
+		counts[key] = len(st.FavedBy)
+	}); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+const userCountTTL = time.Hour
+
+// userCount returns the number of users in the database. The count is cached
+// for userCountTTL since it requires a scan over the user keys, which is done
+// without holding s.mu.
+func (s *server) userCount() (int, error) {
+	s.mu.Lock()
+	count, fresh := s.userCountCache, time.Since(s.userCountTime) < userCountTTL
+	s.mu.Unlock()
+	if fresh {
+		return count, nil
+	}
+
+	count, err := s.countPrefix("user:")
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	s.userCountCache = count
+	s.userCountTime = time.Now()
+	s.mu.Unlock()
+	return count, nil
+}
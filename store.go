@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/pkg/errors"
+)
+
+var storeType = flag.String("store", "badger", "storage backend: badger or memory")
+
+// Store is the ordered key-value storage of the recommender.
+type Store interface {
+	// View runs fn in a read-only transaction.
+	View(fn func(txn Txn) error) error
+	// Update runs fn in a read-write transaction, committing it if fn returns
+	// nil. It returns errConflict if a key read by fn was written concurrently.
+	Update(fn func(txn Txn) error) error
+	Close() error
+}
+
+// Txn is a Store transaction. Values are only valid until it ends.
+type Txn interface {
+	// Get returns the value of the key, or errNotFound.
+	Get(key string) ([]byte, error)
+	// Set sets the key. It returns errTxnTooBig if the transaction has to be
+	// committed first.
+	Set(key string, value []byte) error
+	Delete(key string) error
+	// Scan calls fn with the keys starting with prefix in order, and their
+	// values if values is set. It stops early if fn returns errStopScan.
+	Scan(prefix string, values bool, fn func(key string, value []byte) error) error
+	// ScanFrom is like Scan but starts at the first key >= start.
+	ScanFrom(prefix, start string, values bool, fn func(key string, value []byte) error) error
+}
+
+var (
+	errNotFound  = errors.New("key not found")
+	errConflict  = errors.New("transaction conflict")
+	errTxnTooBig = errors.New("transaction too big")
+	errStopScan  = errors.New("stop scan")
+)
+
+// openStore opens the storage backend selected by the store flag.
+func openStore(path string) (Store, error) {
+	switch *storeType {
+	case "badger":
+		return openBadgerStore(path)
+	case "memory":
+		return newMemoryStore(), nil
+	default:
+		return nil, errors.Errorf("unknown store: %q", *storeType)
+	}
+}
+
+// getStory returns the story, or errNotFound.
+func getStory(txn Txn, key string) (*Story, error) {
+	body, err := txn.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	st := &Story{}
+	if err := st.Unmarshal(body); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func setStory(txn Txn, st Story) error {
+	body, err := st.Marshal()
+	if err != nil {
+		return err
+	}
+	return txn.Set(st.key(), body)
+}
+
+// getUser returns the user, or errNotFound.
+func getUser(txn Txn, key string) (*User, error) {
+	body, err := txn.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	u := &User{}
+	if err := u.Unmarshal(body); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Edges are stored as <prefix><from>|<to> keys.
+
+func setEdge(txn Txn, prefix, from, to string, value []byte) error {
+	return txn.Set(prefix+from+"|"+to, value)
+}
+
+// edges returns the ends of the edges from the key.
+func edges(txn Txn, prefix, from string) ([]string, error) {
+	var out []string
+	p := prefix + from + "|"
+	if err := txn.Scan(p, false, func(key string, _ []byte) error {
+		out = append(out, key[len(p):])
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"github.com/dgraph-io/badger"
+)
+
+// badgerStore is a Store on disk.
+type badgerStore struct {
+	db *badger.DB
+}
+
+func openBadgerStore(path string) (*badgerStore, error) {
+	opts := badger.DefaultOptions
+	opts.Dir = path
+	opts.ValueDir = path
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &badgerStore{db: db}, nil
+}
+
+func (s *badgerStore) View(fn func(txn Txn) error) error {
+	return s.db.View(func(txn *badger.Txn) error {
+		return fn(badgerTxn{txn})
+	})
+}
+
+func (s *badgerStore) Update(fn func(txn Txn) error) error {
+	err := s.db.Update(func(txn *badger.Txn) error {
+		return fn(badgerTxn{txn})
+	})
+	if err == badger.ErrConflict {
+		return errConflict
+	}
+	return err
+}
+
+func (s *badgerStore) Close() error {
+	return s.db.Close()
+}
+
+type badgerTxn struct {
+	txn *badger.Txn
+}
+
+func (t badgerTxn) Get(key string) ([]byte, error) {
+	item, err := t.txn.Get([]byte(key))
+	if err == badger.ErrKeyNotFound {
+		return nil, errNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return item.Value()
+}
+
+func (t badgerTxn) Set(key string, value []byte) error {
+	err := t.txn.Set([]byte(key), value)
+	if err == badger.ErrTxnTooBig {
+		return errTxnTooBig
+	}
+	return err
+}
+
+func (t badgerTxn) Delete(key string) error {
+	err := t.txn.Delete([]byte(key))
+	if err == badger.ErrTxnTooBig {
+		return errTxnTooBig
+	}
+	return err
+}
+
+func (t badgerTxn) Scan(prefix string, values bool, fn func(key string, value []byte) error) error {
+	return t.ScanFrom(prefix, prefix, values, fn)
+}
+
+func (t badgerTxn) ScanFrom(prefix, start string, values bool, fn func(key string, value []byte) error) error {
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = values
+	it := t.txn.NewIterator(opts)
+	defer it.Close()
+	p := []byte(prefix)
+	for it.Seek([]byte(start)); it.ValidForPrefix(p); it.Next() {
+		item := it.Item()
+		var value []byte
+		if values {
+			var err error
+			value, err = item.Value()
+			if err != nil {
+				return err
+			}
+		}
+		if err := fn(string(item.Key()), value); err == errStopScan {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// memoryStore is a Store kept in memory, for embedding the recommender with
+// test data. Transactions are serialized so they never conflict.
+type memoryStore struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{data: make(map[string][]byte)}
+}
+
+func (s *memoryStore) View(fn func(txn Txn) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return fn(&memoryTxn{store: s})
+}
+
+func (s *memoryStore) Update(fn func(txn Txn) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	txn := &memoryTxn{store: s, writes: make(map[string][]byte)}
+	if err := fn(txn); err != nil {
+		return err
+	}
+	for key, value := range txn.writes {
+		if value == nil {
+			delete(s.data, key)
+		} else {
+			s.data[key] = value
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
+
+// memoryTxn buffers the writes of an update until it's committed. Deleted keys
+// are written as nil.
+type memoryTxn struct {
+	store  *memoryStore
+	writes map[string][]byte
+}
+
+func (t *memoryTxn) get(key string) ([]byte, bool) {
+	if value, ok := t.writes[key]; ok {
+		return value, value != nil
+	}
+	value, ok := t.store.data[key]
+	return value, ok
+}
+
+func (t *memoryTxn) Get(key string) ([]byte, error) {
+	value, ok := t.get(key)
+	if !ok {
+		return nil, errNotFound
+	}
+	return value, nil
+}
+
+func (t *memoryTxn) Set(key string, value []byte) error {
+	if t.writes == nil {
+		return errors.New("read-only transaction")
+	}
+	// Like badger, keep the value of an empty key distinct from a deleted one.
+	t.writes[key] = append([]byte{}, value...)
+	return nil
+}
+
+func (t *memoryTxn) Delete(key string) error {
+	if t.writes == nil {
+		return errors.New("read-only transaction")
+	}
+	t.writes[key] = nil
+	return nil
+}
+
+func (t *memoryTxn) Scan(prefix string, values bool, fn func(key string, value []byte) error) error {
+	return t.ScanFrom(prefix, prefix, values, fn)
+}
+
+func (t *memoryTxn) ScanFrom(prefix, start string, values bool, fn func(key string, value []byte) error) error {
+	var keys []string
+	for key := range t.store.data {
+		if _, ok := t.writes[key]; !ok && strings.HasPrefix(key, prefix) && key >= start {
+			keys = append(keys, key)
+		}
+	}
+	for key, value := range t.writes {
+		if value != nil && strings.HasPrefix(key, prefix) && key >= start {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		var value []byte
+		if values {
+			value, _ = t.get(key)
+		}
+		if err := fn(key, value); err == errStopScan {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
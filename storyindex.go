@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Stories are indexed by category and author in favorites order:
+//
+//	bycategory:<normalized category>|<order>|<story key> -> ""
+//	byauthor:<author user key>|<order>|<story key> -> ""
+//
+// where order is favoritesOrder(story.Favorites). The entries are written in
+// the transaction saving the story.
+const (
+	categoryIndexPrefix = "bycategory:"
+	authorIndexPrefix   = "byauthor:"
+)
+
+// favoritesOrder formats favorites so keys sort by descending favorites.
+func favoritesOrder(favorites int32) string {
+	return fmt.Sprintf("%010d", math.MaxInt32-int64(favorites))
+}
+
+// storyIndexKeys returns the index entries of the story.
+func storyIndexKeys(txn Txn, st *Story) ([]string, error) {
+	var keys []string
+	key := st.key()
+	order := favoritesOrder(st.Favorites)
+	if category := normalize(st.Category); category != "" {
+		keys = append(keys, categoryIndexPrefix+category+"|"+order+"|"+key)
+	}
+	author := st.authorKey()
+	if author == "" {
+		var err error
+		if author, err = storyAuthor(txn, key); err != nil {
+			return nil, err
+		}
+	}
+	if author != "" {
+		keys = append(keys, authorIndexPrefix+author+"|"+order+"|"+key)
+	}
+	return keys, nil
+}
+
+// indexStory replaces the index entries of old, which can be nil, with the
+// ones of st.
+func indexStory(txn Txn, old, st *Story) error {
+	keys, err := storyIndexKeys(txn, st)
+	if err != nil {
+		return err
+	}
+	if old != nil {
+		oldKeys, err := storyIndexKeys(txn, old)
+		if err != nil {
+			return err
+		}
+		for _, key := range oldKeys {
+			if strContains(keys, key) {
+				continue
+			}
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+	for _, key := range keys {
+		if err := txn.Set(key, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexedStories returns a page of the stories in the index under prefix.
+func (s *server) indexedStories(prefix string, offset, limit int) ([]*Story, error) {
+	var keys []string
+	skipped := 0
+	if err := s.db.View(func(txn Txn) error {
+		return txn.Scan(prefix, false, func(key string, _ []byte) error {
+			if len(keys) >= limit {
+				return errStopScan
+			}
+			if skipped < offset {
+				skipped++
+				return nil
+			}
+			keys = append(keys, key[strings.LastIndex(key, "|")+1:])
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	var stories []*Story
+	if err := s.forStories(keys, func(key string, st *Story) {
+		st.annotate()
+		stories = append(stories, st)
+	}); err != nil {
+		return nil, err
+	}
+	return stories, nil
+}
+
+type storyListResp struct {
+	Stories []*Story
+}
+
+// handleStoryList serves a page of the index entries under the prefix returned
+// by indexPrefix.
+func (s *server) handleStoryList(indexPrefix func(r *http.Request) (string, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		limit := requestFormInt(r, "limit", 100)
+		offset := requestFormInt(r, "offset", 0)
+		if limit > 200 || limit < 0 {
+			http.Error(w, "limit must be  <= 200 && >= 0", 400)
+			return
+		}
+		if offset < 0 {
+			http.Error(w, "offset must be  >= 0", 400)
+			return
+		}
+		prefix, err := indexPrefix(r)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		stories, err := s.indexedStories(prefix, offset, limit)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		writeJSONP(w, r, storyListResp{stories})
+	}
+}
+
+// categoryStoriesPrefix returns the index prefix of the category parameter.
+func categoryStoriesPrefix(r *http.Request) (string, error) {
+	category := normalize(r.FormValue("category"))
+	if category == "" {
+		return "", errors.New("category is required")
+	}
+	return categoryIndexPrefix + category + "|", nil
+}
+
+// authorStoriesPrefix returns the index prefix of the author profile url
+// parameter.
+func authorStoriesPrefix(r *http.Request) (string, error) {
+	author, ok := parseUserURL(r.FormValue("url"))
+	if !ok {
+		return "", errors.New("unknown author")
+	}
+	return authorIndexPrefix + author.key() + "|", nil
+}
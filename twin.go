@@ -0,0 +1,262 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Works cross-posted to several sites are linked by their normalized title and
+// author name:
+//
+//	twin:<title>|<author>|<story key> -> author user key
+//	twinof:<story key> -> <title>|<author>
+//	authorlink:<user key>|<user key> -> ""
+//
+// Author links are written both ways, when the same author name posted the
+// same title on two sites or with the link-authors command.
+const (
+	twinPrefix       = "twin:"
+	twinOfPrefix     = "twinof:"
+	authorLinkPrefix = "authorlink:"
+)
+
+// normalize lower cases s and drops everything but letters and digits.
+func normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+// keySite returns the site name of a story or user key.
+func keySite(key string) string {
+	parts := strings.SplitN(key, ":", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+// saveTwin records that author wrote the story, linking the author to the
+// authors of the same title on other sites.
+func (s *server) saveTwin(title, author, authorKey, story string) error {
+	prefix := normalize(title) + "|" + normalize(author)
+	if prefix == "|" {
+		return nil
+	}
+	twins, err := s.scanTwins(prefix)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(txn Txn) error {
+		if err := setEdge(txn, twinPrefix, prefix, story, []byte(authorKey)); err != nil {
+			return err
+		}
+		if err := txn.Set(twinOfPrefix+story, []byte(prefix)); err != nil {
+			return err
+		}
+		for twin, twinAuthor := range twins {
+			if keySite(twin) == keySite(story) || twinAuthor == "" || twinAuthor == authorKey {
+				continue
+			}
+			if err := setAuthorLink(txn, authorKey, twinAuthor); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func setAuthorLink(txn Txn, a, b string) error {
+	if err := setEdge(txn, authorLinkPrefix, a, b, nil); err != nil {
+		return err
+	}
+	return setEdge(txn, authorLinkPrefix, b, a, nil)
+}
+
+// scanTwins returns the stories and their author keys under the twin prefix.
+func (s *server) scanTwins(prefix string) (map[string]string, error) {
+	twins := make(map[string]string)
+	p := twinPrefix + prefix + "|"
+	if err := s.db.View(func(txn Txn) error {
+		return txn.Scan(p, true, func(key string, val []byte) error {
+			twins[key[len(p):]] = string(val)
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return twins, nil
+}
+
+// storyAuthors returns the author user keys of the stories whose author is
+// known.
+func (s *server) storyAuthors(keys []string) (map[string]string, error) {
+	authors := make(map[string]string)
+	if err := s.db.View(func(txn Txn) error {
+		for _, key := range keys {
+			var author string
+			st, err := getStory(txn, key)
+			if err == nil {
+				author = st.authorKey()
+			} else if err != errNotFound {
+				return err
+			}
+			if author == "" {
+				// Stories saved before authors were recorded only have a twin.
+				if author, err = storyAuthor(txn, key); err != nil {
+					return err
+				}
+			}
+			if author != "" {
+				authors[key] = author
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return authors, nil
+}
+
+// storyAuthor returns the author user key of the story from its twin, or "" if
+// it's unknown.
+func storyAuthor(txn Txn, key string) (string, error) {
+	prefix, err := txn.Get(twinOfPrefix + key)
+	if err == errNotFound {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	author, err := txn.Get(twinPrefix + string(prefix) + "|" + key)
+	if err == errNotFound {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return string(author), nil
+}
+
+// linkedAuthors returns the user keys of the other identities of an author.
+func (s *server) linkedAuthors(authorKey string) ([]string, error) {
+	var linked []string
+	if err := s.db.View(func(txn Txn) error {
+		var err error
+		linked, err = edges(txn, authorLinkPrefix, authorKey)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return linked, nil
+}
+
+// twinsOf returns the copies of the story posted on other sites, by the same
+// author name or a linked author identity.
+func (s *server) twinsOf(story string) ([]string, error) {
+	var prefix string
+	if err := s.db.View(func(txn Txn) error {
+		val, err := txn.Get(twinOfPrefix + story)
+		if err != nil {
+			return err
+		}
+		prefix = string(val)
+		return nil
+	}); err == errNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	twins, err := s.scanTwins(prefix)
+	if err != nil {
+		return nil, err
+	}
+	prefixes := map[string]bool{prefix: true}
+	title := strings.SplitN(prefix, "|", 2)[0]
+	if authorKey := twins[story]; authorKey != "" {
+		linked, err := s.linkedAuthors(authorKey)
+		if err != nil {
+			return nil, err
+		}
+		users, err := s.existingUsersByKeys(linked)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			p := title + "|" + normalize(u.Name)
+			if prefixes[p] {
+				continue
+			}
+			prefixes[p] = true
+			more, err := s.scanTwins(p)
+			if err != nil {
+				return nil, err
+			}
+			for twin, author := range more {
+				twins[twin] = author
+			}
+		}
+	}
+
+	var out []string
+	for twin := range twins {
+		if keySite(twin) != keySite(story) {
+			out = append(out, twin)
+		}
+	}
+	return out, nil
+}
+
+// expandTwins adds the cross-posted copies of the seeds as seeds of the same
+// weight.
+func (s *server) expandTwins(seeds []seed) ([]seed, error) {
+	seen := make(map[string]bool, len(seeds))
+	for _, seed := range seeds {
+		seen[seed.Key] = true
+	}
+	out := seeds
+	for _, sd := range seeds {
+		twins, err := s.twinsOf(sd.Key)
+		if err != nil {
+			return nil, err
+		}
+		twins, err = s.existingKeys(twins)
+		if err != nil {
+			return nil, err
+		}
+		for _, twin := range twins {
+			if seen[twin] {
+				continue
+			}
+			seen[twin] = true
+			out = append(out, seed{twin, sd.Weight})
+		}
+	}
+	return out, nil
+}
+
+// cmdLinkAuthors links the identities of an author given their profile urls.
+func (s *server) cmdLinkAuthors(urls []string) error {
+	var keys []string
+	for _, url := range urls {
+		u, ok := matchUserURL(s, url)
+		if !ok {
+			return errors.Errorf("unknown user: %q", url)
+		}
+		keys = append(keys, u.key())
+	}
+	return s.db.Update(func(txn Txn) error {
+		for i, a := range keys {
+			for _, b := range keys[i+1:] {
+				if err := setAuthorLink(txn, a, b); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}